@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/vvvvv/ver/pkg/ver"
+	git "gopkg.in/libgit2/git2go.v25"
+)
+
+var changelogCmd = &cobra.Command{
+	Use:     "changelog",
+	Short:   "Generate a markdown changelog from conventional commits between version tags",
+	Example: "$ ver changelog --since v0.2.0\n$ ver changelog --unreleased --file CHANGELOG.md",
+	RunE:    changelogCmdFn,
+}
+
+func changelogCmdFn(cmd *cobra.Command, args []string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	component := componentFlag(cmd)
+
+	versions, err := ver.GetVersions(repo, component, tagFormat)
+	if err != nil {
+		return err
+	}
+	sorted := versions.Sorted()
+
+	since, _ := cmd.Flags().GetString("since")
+	unreleased, _ := cmd.Flags().GetBool("unreleased")
+
+	// index of the oldest version to render
+	start := len(sorted)
+	switch {
+	case since != "":
+		v, err := parseVersion(since)
+		if err != nil {
+			return errors.New("Couldn't get version from tag. " + err.Error())
+		}
+
+		start = -1
+		for i, s := range sorted {
+			if s.Equal(v) {
+				start = i + 1
+			}
+		}
+		if start < 0 {
+			return fmt.Errorf("Version `%s` doesn't exist", tagFormat.Format(v))
+		}
+	case !unreleased:
+		if len(sorted) == 0 {
+			return errors.New("No versions found")
+		}
+		start = len(sorted) - 1
+	}
+
+	sections := []ver.ChangelogSection{}
+
+	if unreleased {
+		var from *git.Oid
+		if len(sorted) > 0 {
+			c, err := ver.GetTagCommit(repo, tagFormat.Tag(component, sorted[len(sorted)-1]))
+			if err != nil {
+				return err
+			}
+			from = c.Id()
+		}
+
+		head, err := ver.GetHeadCommit(repo)
+		if err != nil {
+			return err
+		}
+
+		section, err := changelogSection(repo, "", from, head.Id())
+		if err != nil {
+			return err
+		}
+		section.Date = head.Committer().When
+		sections = append(sections, section)
+	}
+
+	// newest first
+	for i := len(sorted) - 1; i >= start; i-- {
+		to, err := ver.GetTagCommit(repo, tagFormat.Tag(component, sorted[i]))
+		if err != nil {
+			return err
+		}
+
+		var from *git.Oid
+		if i > 0 {
+			c, err := ver.GetTagCommit(repo, tagFormat.Tag(component, sorted[i-1]))
+			if err != nil {
+				return err
+			}
+			from = c.Id()
+		}
+
+		section, err := changelogSection(repo, tagVersion(sorted[i]), from, to.Id())
+		if err != nil {
+			return err
+		}
+		section.Date = to.Committer().When
+		sections = append(sections, section)
+	}
+
+	rendered := ver.RenderChangelog(sections)
+
+	file, _ := cmd.Flags().GetString("file")
+	if file == "" {
+		fmt.Print(rendered)
+		return nil
+	}
+
+	existing, err := ioutil.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.New("Unable to read changelog. " + err.Error())
+	}
+
+	err = ioutil.WriteFile(file, []byte(ver.PrependChangelog(string(existing), rendered)), 0644)
+	if err != nil {
+		return errors.New("Unable to write changelog. " + err.Error())
+	}
+
+	logf("Changelog `%s` updated successfully\n", file)
+
+	return nil
+}
+
+func changelogSection(repo *git.Repository, title string, from, to *git.Oid) (ver.ChangelogSection, error) {
+	section := ver.ChangelogSection{Title: title}
+
+	commits, err := ver.GetCommitsBetween(repo, from, to)
+	if err != nil {
+		return section, err
+	}
+
+	for _, c := range commits {
+		section.Entries = append(section.Entries, ver.ChangelogEntry{
+			Id:      c.Id().String(),
+			Author:  c.Author().Name,
+			Message: c.Message(),
+		})
+	}
+
+	return section, nil
+}
+
+func init() {
+	changelogCmd.Flags().String("since", "", "Render every version after this one. e.g. ver changelog --since v0.2.0")
+	changelogCmd.Flags().Bool("unreleased", false, "Render the commits since the latest version")
+	changelogCmd.Flags().StringP("file", "f", "", "Prepend the changelog to this file instead of printing it")
+
+	RootCmd.AddCommand(changelogCmd)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vvvvv/ver/pkg/ver"
+	git "gopkg.in/libgit2/git2go.v25"
+)
+
+// checkRelease refuses to tag if the working tree is dirty, HEAD is detached,
+// the current branch is no release branch or target already carries a version
+// of one of components. Each check can be disabled with its --allow-* flag.
+// A target given by --at also has to be reachable from HEAD and must not be
+// older than the latest version of any of components.
+// With --dry-run failed checks are only printed as warnings.
+func checkRelease(cmd *cobra.Command, repo *git.Repository, target *git.Commit, components ...string) error {
+	failed := []error{}
+
+	if allowDirty, _ := cmd.Flags().GetBool("allow-dirty"); !allowDirty {
+		dirty, err := ver.IsDirty(repo)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			failed = append(failed, errors.New("Working tree has uncommitted changes. Commit them or use --allow-dirty"))
+		}
+	}
+
+	if err := checkBranch(cmd, repo); err != nil {
+		failed = append(failed, err)
+	}
+
+	if at, _ := cmd.Flags().GetString("at"); at != "" {
+		if err := checkTarget(repo, at, target, components); err != nil {
+			failed = append(failed, err)
+		}
+	}
+
+	if allowRetag, _ := cmd.Flags().GetBool("allow-retag"); !allowRetag {
+		tags, err := ver.GetTagsAt(repo, target.Id())
+		if err != nil {
+			return err
+		}
+
+		for _, tag := range tags {
+			namespace, _ := ver.SplitComponent(tag)
+			if !containsString(components, namespace) {
+				continue
+			}
+			if _, err := tagFormat.Parse(tag); err != nil {
+				continue
+			}
+			failed = append(failed, fmt.Errorf("Commit %.7s is already tagged as `%s`. Use --allow-retag to tag it again", target.Id(), tag))
+			break
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		for _, err := range failed {
+			warnf("%s\n", err)
+		}
+		return nil
+	}
+
+	return failed[0]
+}
+
+// checkTarget refuses a target commit which isn't reachable from HEAD or
+// is older than the commit of the latest version of one of components
+func checkTarget(repo *git.Repository, at string, target *git.Commit, components []string) error {
+	head, err := ver.GetHeadCommit(repo)
+	if err != nil {
+		return err
+	}
+
+	ok, err := ver.IsAncestor(repo, head.Id(), target.Id())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("`" + at + "` is not reachable from HEAD. Check out the release branch containing it")
+	}
+
+	for _, component := range components {
+		versions, err := ver.GetVersions(repo, component, tagFormat)
+		if err != nil {
+			return err
+		}
+		if len(versions) == 0 {
+			continue
+		}
+
+		name := tagFormat.Tag(component, versions.Latest())
+		latest, err := ver.GetTagCommit(repo, name)
+		if err != nil {
+			return err
+		}
+
+		ok, err := ver.IsAncestor(repo, target.Id(), latest.Id())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("`" + at + "` is older than the latest version `" + name + "`")
+		}
+	}
+
+	return nil
+}
+
+// targetCommit returns the commit given by --at, HEAD by default
+func targetCommit(cmd *cobra.Command, repo *git.Repository) (*git.Commit, error) {
+	at, _ := cmd.Flags().GetString("at")
+	if at == "" {
+		return ver.GetHeadCommit(repo)
+	}
+
+	return ver.ResolveCommit(repo, at)
+}
+
+// checkBranch refuses a detached HEAD and branches not matching --release-branches
+func checkBranch(cmd *cobra.Command, repo *git.Repository) error {
+	allowDetached, _ := cmd.Flags().GetBool("allow-detached")
+
+	branch, err := ver.GetCurrentBranch(repo)
+	if err != nil {
+		if allowDetached {
+			return nil
+		}
+		return errors.New("Refusing to tag. " + err.Error() + ". Check out a release branch or use --allow-detached")
+	}
+
+	branches, _ := cmd.Flags().GetStringSlice("release-branches")
+	if len(branches) > 0 && !ver.MatchBranch(branches, branch) {
+		return errors.New("Branch `" + branch + "` is no release branch. Allowed are " + strings.Join(branches, ", "))
+	}
+
+	return nil
+}
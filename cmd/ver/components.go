@@ -0,0 +1,218 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/vvvvv/ver/pkg/ver"
+	git "gopkg.in/libgit2/git2go.v25"
+)
+
+var listComponentsCmd = &cobra.Command{
+	Use:   "list-components",
+	Short: "List the components found in tags and the config with their latest version",
+	RunE:  listComponentsCmdFn,
+}
+
+// componentInfo describes a component in the output of list-components
+type componentInfo struct {
+	Name    string `json:"name"`
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"`
+	Tag     string `json:"tag,omitempty"`
+}
+
+func listComponentsCmdFn(cmd *cobra.Command, args []string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	names, err := allComponents(repo)
+	if err != nil {
+		return err
+	}
+
+	infos := []componentInfo{}
+	for _, name := range names {
+		info := componentInfo{Name: name}
+		if c, ok := config.Component(name); ok {
+			info.Path = c.Path
+		}
+
+		versions, err := ver.GetVersions(repo, name, tagFormat)
+		if err != nil {
+			return err
+		}
+		if len(versions) > 0 {
+			latest := versions.Latest()
+			info.Version = tagVersion(latest)
+			info.Tag = tagFormat.Tag(name, latest)
+		}
+
+		infos = append(infos, info)
+	}
+
+	switch output {
+	case outputJSON:
+		return printJSON(infos)
+	case outputPlain:
+		for _, info := range infos {
+			fmt.Println(info.Name)
+		}
+		return nil
+	}
+
+	for _, info := range infos {
+		version := info.Version
+		if version == "" {
+			version = "-"
+		}
+		path := info.Path
+		if path == "" {
+			path = "-"
+		}
+		fmt.Printf("%-30s %-15s %s\n", info.Name, version, path)
+	}
+
+	return nil
+}
+
+// allComponents returns the components found in tags and the config, sorted
+func allComponents(repo *git.Repository) ([]string, error) {
+	tags, err := repo.Tags.List()
+	if err != nil {
+		return nil, errors.New("Tags could not be loaded. " + err.Error())
+	}
+
+	names := ver.DiscoverComponents(tags, tagFormat)
+	for _, c := range config.Components {
+		name := ver.NormalizeComponent(c.Name)
+		if !containsString(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+var changedCmd = &cobra.Command{
+	Use:   "changed",
+	Short: "List the components with commits in their directory since their latest version",
+	RunE:  changedCmdFn,
+}
+
+// changedInfo describes a component in the output of changed
+type changedInfo struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Version string `json:"version,omitempty"`
+	Commits int    `json:"commits"`
+}
+
+func changedCmdFn(cmd *cobra.Command, args []string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	infos, err := changedComponents(repo)
+	if err != nil {
+		return err
+	}
+
+	switch output {
+	case outputJSON:
+		return printJSON(infos)
+	case outputPlain:
+		for _, info := range infos {
+			fmt.Println(info.Name)
+		}
+		return nil
+	}
+
+	if len(infos) == 0 {
+		fmt.Println("No component has unreleased changes")
+		return nil
+	}
+
+	for _, info := range infos {
+		version := info.Version
+		if version == "" {
+			version = "-"
+		}
+		fmt.Printf("%-30s %-15s %d commits in %s\n", info.Name, version, info.Commits, info.Path)
+	}
+
+	return nil
+}
+
+// changedComponents returns the components with commits in their directory
+// since their latest version
+func changedComponents(repo *git.Repository) ([]changedInfo, error) {
+	names, err := allComponents(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := []changedInfo{}
+	for _, name := range names {
+		c, ok := config.Component(name)
+		if !ok {
+			c = ver.Component{Name: name}
+		}
+		info := changedInfo{Name: name, Path: c.Dir()}
+
+		versions, err := ver.GetVersions(repo, name, tagFormat)
+		if err != nil {
+			return nil, err
+		}
+
+		var since *git.Oid
+		if len(versions) > 0 {
+			latest := versions.Latest()
+			info.Version = tagVersion(latest)
+
+			tag, err := ver.GetTagCommit(repo, tagFormat.Tag(name, latest))
+			if err != nil {
+				return nil, err
+			}
+			since = tag.Id()
+		}
+
+		commits, err := ver.GetCommitsSince(repo, since)
+		if err != nil {
+			return nil, err
+		}
+		commits, err = ver.FilterCommitsByPath(repo, commits, info.Path)
+		if err != nil {
+			return nil, err
+		}
+		info.Commits = len(commits)
+
+		if info.Commits > 0 {
+			infos = append(infos, info)
+		}
+	}
+
+	return infos, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	RootCmd.AddCommand(
+		listComponentsCmd,
+		changedCmd,
+	)
+}
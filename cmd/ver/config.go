@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/vvvvv/ver/pkg/ver"
+)
+
+// config is the repository configuration, merged with the environment.
+// Flags take precedence over the environment, which takes precedence over
+// the config file, which takes precedence over the flag defaults.
+var config = &ver.Config{Settings: map[string]string{}}
+
+// setting is a single effective configuration value and where it came from
+type setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// settings are the effective values in the order they were resolved
+var settings = []setting{}
+
+// loadConfig reads the config file from the root of the repository in the
+// working directory. Outside of a repository there is nothing to load.
+func loadConfig() error {
+	repo, err := openRepository()
+	if err != nil {
+		return nil
+	}
+	defer repo.Free()
+
+	if repo.IsBare() {
+		return nil
+	}
+
+	conf, err := ver.LoadConfig(repo.Workdir())
+	if err != nil {
+		return err
+	}
+	config = conf
+
+	return nil
+}
+
+// envFlags are the flags read from VER_* environment variables.
+// Flags of a single run like --major, --dry-run or --component are left out.
+var envFlags = map[string]bool{
+	"prefix":           true,
+	"suffix":           true,
+	"alt-prefixes":     true,
+	"push":             true,
+	"remote":           true,
+	"ssh-key":          true,
+	"tagger-name":      true,
+	"tagger-email":     true,
+	"lightweight":      true,
+	"sign":             true,
+	"signing-key":      true,
+	"allow-dirty":      true,
+	"allow-retag":      true,
+	"allow-detached":   true,
+	"release-branches": true,
+	"message":          true,
+	"message-file":     true,
+	"bump-message":     true,
+	"source":           true,
+}
+
+// applyConfig sets every flag which wasn't given on the command line
+// from the environment or the config file
+func applyConfig(cmd *cobra.Command) error {
+	var err error
+
+	settings = settings[:0]
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Name == "help" {
+			return
+		}
+
+		// empty values count, e.g. `prefix: ""` for tags like 1.2.3
+		env, inEnv := os.LookupEnv(ver.EnvName(f.Name))
+		value, inFile := config.Settings[f.Name]
+
+		source := "default"
+		switch {
+		case f.Changed:
+			source = "flag --" + f.Name
+		case envFlags[f.Name] && inEnv:
+			source = "env " + ver.EnvName(f.Name)
+			err = cmd.Flags().Set(f.Name, env)
+		case inFile:
+			source = "file " + config.Path
+			err = cmd.Flags().Set(f.Name, value)
+		}
+		if err != nil {
+			err = fmt.Errorf("Invalid value for `%s` from %s. %s", f.Name, source, err)
+			return
+		}
+
+		settings = append(settings, setting{Key: f.Name, Value: f.Value.String(), Source: source})
+	})
+	if err != nil {
+		return err
+	}
+
+	// bump rules only fall back to the defaults as a whole
+	bumpSource := "file " + config.Path
+	if config.Bump.IsZero() {
+		config.Bump = ver.DefaultBumpRules
+		bumpSource = "default"
+	}
+
+	rules := []struct {
+		key  string
+		list *[]string
+	}{
+		{"bump.major", &config.Bump.Major},
+		{"bump.minor", &config.Bump.Minor},
+		{"bump.patch", &config.Bump.Patch},
+	}
+	for _, rule := range rules {
+		source := bumpSource
+		// an empty variable turns the rule off like an empty list in the file
+		if env, ok := os.LookupEnv(ver.EnvName(rule.key)); ok {
+			*rule.list = []string{}
+			if env != "" {
+				*rule.list = strings.Split(env, ",")
+			}
+			source = "env " + ver.EnvName(rule.key)
+		}
+		settings = append(settings, setting{Key: rule.key, Value: strings.Join(*rule.list, ","), Source: source})
+	}
+
+	for _, rule := range config.Branches {
+		channel := rule.Prerelease
+		if channel == "" {
+			channel = "final"
+		}
+		settings = append(settings, setting{Key: "branches." + rule.Branch, Value: channel, Source: "file " + config.Path})
+	}
+
+	return nil
+}
+
+// tagFormat is the format of the tag names given by --prefix, --suffix and --alt-prefixes
+var tagFormat = ver.DefaultTagFormat
+
+func setTagFormat(cmd *cobra.Command) {
+	tagFormat.Prefix, _ = cmd.Flags().GetString("prefix")
+	tagFormat.Suffix, _ = cmd.Flags().GetString("suffix")
+	tagFormat.Alternatives, _ = cmd.Flags().GetStringSlice("alt-prefixes")
+}
+
+// parseVersion parses a version given on the command line,
+// with or without prefix and suffix
+func parseVersion(s string) (ver.Version, error) {
+	format := ver.TagFormat{
+		Prefix:       tagFormat.Prefix,
+		Alternatives: append([]string{""}, tagFormat.Alternatives...),
+	}
+
+	v, err := format.Parse(strings.TrimSuffix(s, tagFormat.Suffix))
+	if err != nil {
+		return v, err
+	}
+
+	// it doesn't name an existing tag
+	v.Tag = ""
+	return v, nil
+}
+
+// preRun runs before every command
+func preRun(cmd *cobra.Command, args []string) error {
+	if err := loadConfig(); err != nil {
+		return err
+	}
+
+	if err := applyConfig(cmd); err != nil {
+		return err
+	}
+
+	setTagFormat(cmd)
+
+	return setOutput(cmd, args)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective configuration and where each value came from",
+	Long: "Show the effective configuration and where each value came from.\n" +
+		"Flags take precedence over VER_* environment variables, which take precedence over " +
+		strings.Join(ver.ConfigFiles, ", ") + " in the repository root.\n" +
+		"Only settings like the prefix, the remote, pushing, signing and the release checks are read from the environment,\n" +
+		"not the flags of a single run like --major or --dry-run. --output env writes VER_OUT_* variables,\n" +
+		"which are never read back as settings.",
+	RunE: configShowCmdFn,
+}
+
+func configShowCmdFn(cmd *cobra.Command, args []string) error {
+	shown := settings
+
+	// settings of other commands
+	for _, key := range config.Keys() {
+		if cmd.Flags().Lookup(key) == nil {
+			shown = append(shown, setting{Key: key, Value: config.Settings[key], Source: "file " + config.Path})
+		}
+	}
+
+	if output == outputJSON {
+		return printJSON(shown)
+	}
+
+	width := 0
+	for _, s := range shown {
+		if len(s.Key) > width {
+			width = len(s.Key)
+		}
+	}
+
+	for _, s := range shown {
+		fmt.Printf("%-*s  %-20s  %s\n", width, s.Key, s.Value, s.Source)
+	}
+
+	return nil
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+	RootCmd.AddCommand(configCmd)
+}
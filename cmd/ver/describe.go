@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vvvvv/ver/pkg/ver"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe",
+	Short: "Print a development version for HEAD without creating a tag",
+	Long: "Print a SemVer development version for HEAD based on the nearest version tag, like git describe.\n" +
+		"The version is the next patch release (or minor/major with -m/-M) with the channel and the number of\n" +
+		"commits since the tag as prerelease and the abbreviated commit id as build metadata.\n" +
+		"Uncommitted changes add a `.dirty` marker. A clean, tagged HEAD is described by its own version.",
+	Example: "$ ver describe\n v1.4.1-dev.7+g27c1f12\n$ ver describe -m --channel nightly\n v1.5.0-nightly.7+g27c1f12.dirty",
+	RunE:    describeCmdFn,
+}
+
+func describeCmdFn(cmd *cobra.Command, args []string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	channel, _ := cmd.Flags().GetString("channel")
+	if err := ver.ValidatePrerelease(channel); err != nil {
+		return errors.New("Invalid channel. " + err.Error())
+	}
+
+	kind := ver.BumpPatch
+	if minor, _ := cmd.Flags().GetBool("minor"); minor {
+		kind = ver.BumpMinor
+	}
+	if major, _ := cmd.Flags().GetBool("major"); major {
+		kind = ver.BumpMajor
+	}
+
+	d, err := ver.Describe(repo, componentFlag(cmd), tagFormat)
+	if err != nil {
+		return err
+	}
+
+	v := d.DevVersion(channel, kind)
+
+	if output == outputText {
+		fmt.Printf("%s\n", tagFormat.Format(v))
+		return nil
+	}
+
+	res := newResult(v)
+	res.Component = componentFlag(cmd)
+	res.Tag = d.Tag
+	if d.Tag != "" {
+		res.PreviousVersion = tagVersion(d.Version)
+	}
+	if head, err := ver.GetHeadCommit(repo); err == nil {
+		res.Commit = head.Id().String()
+	}
+
+	return printResult(res)
+}
+
+func init() {
+	describeCmd.Flags().BoolP("major", "M", false, "Base the version on the next major version")
+	describeCmd.Flags().BoolP("minor", "m", false, "Base the version on the next minor version")
+	describeCmd.Flags().String("channel", "dev", "Prerelease channel of the version")
+
+	RootCmd.AddCommand(describeCmd)
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/vvvvv/ver/pkg/ver"
+	git "gopkg.in/libgit2/git2go.v25"
+)
+
+// defaultBumpMessage is the commit message template of --bump-files
+const defaultBumpMessage = "chore(release): {{.Version}}"
+
+// versionFiles returns the configured version files, or the known ones
+// existing in the directory of the component.
+// Paths are relative to the repository root.
+func versionFiles(cmd *cobra.Command, repo *git.Repository) ([]ver.VersionFile, error) {
+	dir := componentDir(cmd)
+
+	files := []ver.VersionFile{}
+	for _, f := range config.Files {
+		f.Path = path.Join(dir, f.Path)
+		files = append(files, f)
+	}
+
+	if len(files) == 0 {
+		for _, name := range ver.DefaultVersionFiles {
+			p := path.Join(dir, name)
+			if _, err := os.Stat(filepath.Join(repo.Workdir(), p)); err == nil {
+				files = append(files, ver.VersionFile{Path: p})
+			}
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("No version files found. Configure them in `files`")
+	}
+
+	return files, nil
+}
+
+// componentDir returns the directory of the selected component,
+// empty for the repository root
+func componentDir(cmd *cobra.Command) string {
+	component := componentFlag(cmd)
+	if component == "" {
+		return ""
+	}
+
+	c, ok := config.Component(component)
+	if !ok {
+		c = ver.Component{Name: component}
+	}
+	return c.Dir()
+}
+
+// versionBump is a commit of --bump-files which can be undone
+// if the tag can't be created after all
+type versionBump struct {
+	head     *git.Commit
+	contents map[string][]byte
+}
+
+// undo moves the branch back to the commit before the bump and restores
+// the version files. Changes staged before the bump stay in the working tree.
+func (b *versionBump) undo(repo *git.Repository) error {
+	if err := repo.ResetToCommit(b.head, git.ResetMixed, nil); err != nil {
+		return errors.New("Unable to reset to " + b.head.Id().String() + ". " + err.Error())
+	}
+
+	for file, content := range b.contents {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(file, content, info.Mode()); err != nil {
+			return errors.New("Unable to restore version file. " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+// bumpFiles writes v into the version files and commits them,
+// so the tag is created on the new commit.
+// Nothing is committed with --dry-run, the returned bump is nil then.
+func bumpFiles(cmd *cobra.Command, repo *git.Repository, previous ver.Version, v ver.Version) (*versionBump, error) {
+	if at, _ := cmd.Flags().GetString("at"); at != "" {
+		return nil, errors.New("--bump-files can't be combined with --at")
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	if _, err := ver.GetCurrentBranch(repo); err != nil {
+		if !dryRun {
+			return nil, errors.New("--bump-files needs a branch to commit to. " + err.Error())
+		}
+		warnf("--bump-files needs a branch to commit to. %s\n", err)
+	}
+
+	head, err := ver.GetHeadCommit(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	// the checks have to pass before anything is committed
+	if err := checkRelease(cmd, repo, head, componentFlag(cmd)); err != nil {
+		return nil, err
+	}
+	if err := checkTag(cmd, repo, previous, v, head); err != nil {
+		return nil, err
+	}
+
+	files, err := versionFiles(cmd, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	bump := &versionBump{head: head, contents: map[string][]byte{}}
+	contents := map[string][]byte{}
+	paths := []string{}
+	for _, f := range files {
+		file := filepath.Join(repo.Workdir(), f.Path)
+
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.New("Unable to read version file. " + err.Error())
+		}
+		bump.contents[file] = content
+
+		content, err = ver.SetFileVersion(f, content, v)
+		if err != nil {
+			return nil, err
+		}
+
+		contents[file] = content
+		paths = append(paths, f.Path)
+	}
+
+	user, err := tagger(cmd, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	text, _ := cmd.Flags().GetString("bump-message")
+	message, err := ver.RenderTagMessage(text, ver.TagMessageData{
+		Component: componentFlag(cmd),
+		Previous:  previous,
+		Version:   v,
+		Tag:       tagFormat.Tag(componentFlag(cmd), v),
+		Author:    user.Name,
+		Email:     user.Email,
+		Date:      user.When,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		for _, p := range paths {
+			logf("Dry run, `%s` would be set to %s\n", p, v.String())
+		}
+		logf("Dry run, not committed: %s", message)
+		return nil, nil
+	}
+
+	for _, p := range paths {
+		file := filepath.Join(repo.Workdir(), p)
+		info, err := os.Stat(file)
+		if err != nil {
+			return bump, err
+		}
+		if err := ioutil.WriteFile(file, contents[file], info.Mode()); err != nil {
+			return bump, errors.New("Unable to write version file. " + err.Error())
+		}
+		logf("`%s` set to %s\n", p, v.String())
+	}
+
+	commit, err := ver.CommitFiles(repo, paths, user, message)
+	if err != nil {
+		return bump, err
+	}
+
+	logf("Committed %.7s %s\n", commit.Id(), commit.Summary())
+
+	return bump, nil
+}
+
+// checkTag makes sure the tag of v can be created on top of head,
+// so --bump-files doesn't leave a commit without a tag behind
+func checkTag(cmd *cobra.Command, repo *git.Repository, previous ver.Version, v ver.Version, head *git.Commit) error {
+	name := tagFormat.Tag(componentFlag(cmd), v)
+	if ref, err := repo.References.Lookup("refs/tags/" + name); err == nil {
+		ref.Free()
+		return errors.New("Tag `" + name + "` already exists")
+	}
+
+	user, err := tagger(cmd, repo)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tagSigner(cmd, repo); err != nil {
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); !dryRun {
+			return err
+		}
+		warnf("%s\n", err)
+	}
+
+	if !lightweight(cmd) {
+		if _, err := tagMessage(cmd, repo, componentFlag(cmd), previous, v, user, head); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	incrementCmd.Flags().Bool("bump-files", false, "Write the new version into package.json, Cargo.toml, pyproject.toml, Chart.yaml, version.go or the configured files and commit them before tagging")
+	incrementCmd.Flags().String("bump-message", defaultBumpMessage, "Go text/template for the commit message of --bump-files")
+}
@@ -4,8 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/vvvvv/ver/pkg/ver"
@@ -24,81 +24,42 @@ var RootCmd = &cobra.Command{
 	Long:    "ver increments semver style git tags",
 	Example: "$ ver -m -p\n Tag `v0.2.1` created successfully\n 27c1f1234188aa11585334726f8721d9a35038eb",
 	RunE:    rootCmdFn,
+
+	PersistentPreRunE: preRun,
 }
 
 func rootCmdFn(cmd *cobra.Command, args []string) error {
-	ver.Prefix, _ = cmd.Flags().GetString("prefix")
-
-	pwd, err := os.Getwd()
-	if err != nil {
-		return errors.New("Unable to get working directory. " + err.Error())
-	}
-
-	repo, err := git.OpenRepository(pwd)
-	if err != nil {
-		return errors.New("Directory doesn't appear to be a git repository. " + err.Error())
-	}
-
-	tags, err := repo.Tags.List()
+	repo, err := openRepository()
 	if err != nil {
-		return errors.New("Tags could not be loaded. " + err.Error())
+		return err
 	}
 
 	setToVersion, _ := cmd.Flags().GetString("set")
 	if setToVersion != "" {
-		// has no version prefix
-		// set it explicitly
-		if !strings.HasPrefix(setToVersion, ver.Prefix) {
-			setToVersion = ver.Prefix + setToVersion
-		}
-		v, err := ver.GetVersionFromTag(setToVersion)
+		v, err := parseVersion(setToVersion)
 		if err != nil {
 			return errors.New("Couldn't get version from tag. " + err.Error())
 		}
 
-		user, err := ver.GetGitUser()
+		versions, err := currentVersions(cmd, repo)
 		if err != nil {
 			return err
 		}
 
-		commit, err := ver.GetHeadCommit(repo)
-		if err != nil {
-			return err
-		}
-
-		id, err := repo.Tags.Create(v.String(), commit, user, v.String())
-		if err != nil {
-			return errors.New("Unable to create tag. " + err.Error())
-		}
-
-		fmt.Printf("Tag `%s` created successfully\n%s\n", v, id)
-
-		if pushTags, _ := cmd.Flags().GetBool("push"); pushTags {
-			// this is fucking embarrassing
-			// please look away
-			push := exec.Command("git", "push", "--tags")
-			err = push.Run()
-			if err != nil {
-				return err
-			}
-		}
-
-		return nil
+		return createTag(cmd, repo, versions.Latest(), v)
 	}
 
-	versions := ver.Versions{}
-	for _, tag := range tags {
-		v, err := ver.GetVersionFromTag(tag)
-		if err != nil {
-			continue
-		}
-
-		versions = append(versions, *v)
+	versions, err := currentVersions(cmd, repo)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("%s\n", versions.Latest())
+	if output == outputText {
+		fmt.Printf("%s\n", tagFormat.Tag(componentFlag(cmd), versions.Latest()))
+		return nil
+	}
 
-	return nil
+	return printResult(newResult(versions.Latest()))
 
 }
 
@@ -115,112 +76,475 @@ func versionCmdFn(cmd *cobra.Command, args []string) {
 var incrementCmd = &cobra.Command{
 	Use:   "i",
 	Short: "Used to increment version",
-	RunE:  incrementCmdFn,
+	Long: "Used to increment version.\n" +
+		"Without --pre the branch rules of the config decide the prerelease channel, e.g.\n\n" +
+		"branches:\n" +
+		"  - branch: main\n" +
+		"  - branch: develop\n" +
+		"    prerelease: beta\n" +
+		"  - branch: release/*\n" +
+		"    prerelease: rc\n" +
+		"  - branch: \"*\"\n" +
+		"    prerelease: alpha.{branch}\n\n" +
+		"`{branch}` is replaced by the slug of the branch name. Branches matching no rule can't be released from.",
+	Example: "$ ver i -m --pre rc\n Tag `v0.3.0-rc.1` created successfully\n$ ver i --pre rc\n Tag `v0.3.0-rc.2` created successfully\n$ ver i --promote\n Tag `v0.3.0` created successfully",
+	RunE:    incrementCmdFn,
 }
 
 func incrementCmdFn(cmd *cobra.Command, args []string) error {
-	ver.Prefix, _ = cmd.Flags().GetString("prefix")
-
-	pwd, err := os.Getwd()
+	repo, err := openRepository()
 	if err != nil {
-		return errors.New("Unable to get working directory. " + err.Error())
+		return err
 	}
 
-	repo, err := git.OpenRepository(pwd)
+	versions, err := currentVersions(cmd, repo)
 	if err != nil {
-		return errors.New("Directory doesn't appear to be a git repository. " + err.Error())
+		return err
 	}
 
-	tags, err := repo.Tags.List()
-	if err != nil {
-		return errors.New("Tags could not be loaded. " + err.Error())
-	}
+	latestVer := versions.Latest()
+	newVer := latestVer
+
+	major, _ := cmd.Flags().GetBool("major")
+	minor, _ := cmd.Flags().GetBool("minor")
+	patch, _ := cmd.Flags().GetBool("patch")
+
+	pre, _ := cmd.Flags().GetString("pre")
+	promote, _ := cmd.Flags().GetBool("promote")
+
+	if auto, _ := cmd.Flags().GetBool("auto"); auto {
+		if major || minor || patch || promote {
+			return errors.New("--auto can't be combined with -M, -m, -p or --promote")
+		}
 
-	versions := ver.Versions{}
-	for _, tag := range tags {
-		v, err := ver.GetVersionFromTag(tag)
+		target, err := targetCommit(cmd, repo)
 		if err != nil {
-			continue
-			//return errors.New("Couldn't get version from tag. " + err.Error())
+			return err
+		}
+
+		kind, err := detectBump(repo, target.Id(), componentFlag(cmd), pathFlag(cmd), latestVer)
+		if err != nil {
+			return err
+		}
+
+		if kind == ver.BumpNone {
+			logf("No commits require a new version\n")
+			return nil
 		}
 
-		versions = append(versions, *v)
+		major = kind == ver.BumpMajor
+		minor = kind == ver.BumpMinor
+		patch = kind == ver.BumpPatch
 	}
 
-	latestVer := versions.Latest()
-	newVer := latestVer
+	// without --pre the branch rules decide the prerelease channel
+	branchChannel := false
+	if pre == "" && !promote && len(config.Branches) > 0 {
+		pre, err = branchPrerelease(cmd, repo)
+		if err != nil {
+			return err
+		}
+		branchChannel = pre != ""
+	}
 
-	major, _ := cmd.Flags().GetBool("major")
-	minor, _ := cmd.Flags().GetBool("minor")
-	patch, _ := cmd.Flags().GetBool("patch")
+	if promote && (major || minor || patch || pre != "") {
+		return errors.New("--promote can't be combined with -M, -m, -p or --pre")
+	}
 
 	if major {
-		newVer.Major += 1
-		newVer.Minor = 0
-		newVer.Patch = 0
+		newVer = newVer.BumpMajor()
 	}
 	if minor {
-		newVer.Minor += 1
-		newVer.Patch = 0
+		newVer = newVer.BumpMinor()
 	}
 	if patch {
-		newVer.Patch += 1
+		newVer = newVer.BumpPatch()
+	}
+
+	if pre != "" {
+		if err := ver.ValidatePrerelease(pre); err != nil {
+			return errors.New("Invalid prerelease channel. " + err.Error())
+		}
+
+		// starting a prerelease line from a final release
+		// needs a new version to work towards
+		if !major && !minor && !patch && !latestVer.IsPrerelease() {
+			newVer = newVer.BumpPatch()
+		}
+
+		newVer = versions.NextPrerelease(newVer, pre)
+
+		// branches release their channels side by side, so a branch
+		// prerelease only has to follow the latest final release and its own channel
+		latest := latestVer
+		if branchChannel {
+			latest = versions.Filter(func(v ver.Version) bool {
+				return !v.IsPrerelease() || v.Channel() == pre
+			}).Latest()
+		}
+
+		if !newVer.GreaterThan(latest) {
+			return fmt.Errorf("Prerelease `%s` would not be greater than the latest version `%s`", tagFormat.Format(newVer), tagVersion(latest))
+		}
+	}
+
+	if promote {
+		if !latestVer.IsPrerelease() {
+			return fmt.Errorf("Latest version `%s` is not a prerelease", tagVersion(latestVer))
+		}
+
+		newVer = latestVer.Release()
 	}
 
 	setToVersion, _ := cmd.Flags().GetString("set")
 	if setToVersion != "" {
-		// has no version prefix
-		// set it explicitly
-		if !strings.HasPrefix(setToVersion, ver.Prefix) {
-			setToVersion = ver.Prefix + setToVersion
-		}
-		v, err := ver.GetVersionFromTag(setToVersion)
+		v, err := parseVersion(setToVersion)
 		if err != nil {
 			return errors.New("Couldn't get version from tag. " + err.Error())
 		}
 
-		newVer = *v
+		newVer = v
+	}
+
+	name := tagFormat.Tag(componentFlag(cmd), newVer)
+
+	var bump *versionBump
+	if bumpFlag, _ := cmd.Flags().GetBool("bump-files"); bumpFlag {
+		bump, err = bumpFiles(cmd, repo, latestVer, newVer)
+		if err != nil {
+			return undoBump(repo, bump, name, err)
+		}
+	}
+
+	if err := createTag(cmd, repo, latestVer, newVer); err != nil {
+		return undoBump(repo, bump, name, err)
+	}
+
+	return nil
+
+}
+
+// undoBump undoes the commit of --bump-files after err, if there is one.
+// Once the tag exists, e.g. if only pushing failed, the commit is kept.
+func undoBump(repo *git.Repository, bump *versionBump, name string, err error) error {
+	if bump == nil {
+		return err
+	}
+	if ref, lookupErr := repo.References.Lookup("refs/tags/" + name); lookupErr == nil {
+		ref.Free()
+		return err
+	}
+
+	if undoErr := bump.undo(repo); undoErr != nil {
+		return errors.New(err.Error() + ". Undoing the version bump failed as well. " + undoErr.Error())
+	}
+
+	logf("Version bump undone, the branch is back at %.7s\n", bump.head.Id())
+	return err
+}
+
+// branchPrerelease returns the prerelease channel of the branch rule matching
+// the branch given by --branch or the current branch.
+// An empty channel means a final release.
+func branchPrerelease(cmd *cobra.Command, repo *git.Repository) (string, error) {
+	branch, _ := cmd.Flags().GetString("branch")
+	if branch == "" {
+		var err error
+		branch, err = ver.GetCurrentBranch(repo)
+		if err != nil {
+			return "", errors.New("Unable to apply branch rules. " + err.Error() + ". Use --branch or --pre")
+		}
+	}
+
+	rule, ok := ver.MatchBranchRule(config.Branches, branch)
+	if !ok {
+		return "", errors.New("No branch rule matches branch `" + branch + "`")
+	}
+
+	channel := rule.Channel(branch)
+	if channel == "" {
+		logf("Branch `%s` matches `%s`, releasing a final version\n", branch, rule.Branch)
+	} else {
+		logf("Branch `%s` matches `%s`, releasing a `%s` prerelease\n", branch, rule.Branch, channel)
+	}
+
+	return channel, nil
+}
+
+// tagger returns the identity to tag with, --tagger-name and --tagger-email
+// taking precedence over the environment and the git config.
+// A dry run doesn't need one and only warns if there is none.
+func tagger(cmd *cobra.Command, repo *git.Repository) (*git.Signature, error) {
+	name, _ := cmd.Flags().GetString("tagger-name")
+	email, _ := cmd.Flags().GetString("tagger-email")
+
+	user, err := ver.GetGitUser(repo, name, email)
+	if err != nil {
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			warnf("%s\n", err)
+			return &git.Signature{Name: "unknown", Email: "unknown", When: time.Now()}, nil
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// componentFlag returns the component given by --component.
+// Without it a directory given by --path selects the component configured
+// for it, or the namespace of the same name.
+func componentFlag(cmd *cobra.Command) string {
+	component, _ := cmd.Flags().GetString("component")
+	if component != "" {
+		return ver.NormalizeComponent(component)
+	}
+
+	path, _ := cmd.Flags().GetString("path")
+	if path == "" {
+		return ""
+	}
+	if c, ok := config.ComponentByPath(path); ok {
+		return ver.NormalizeComponent(c.Name)
+	}
+	return ver.NormalizeComponent(path)
+}
+
+// pathFlag returns the directory given by --path or the configured
+// directory of the component. Commits outside of it don't count towards a release.
+func pathFlag(cmd *cobra.Command) string {
+	path, _ := cmd.Flags().GetString("path")
+	if path != "" {
+		return strings.Trim(path, "/")
+	}
+
+	if c, ok := config.Component(componentFlag(cmd)); ok && c.Path != "" {
+		return c.Dir()
+	}
+	return ""
+}
+
+// openRepository opens the git repository of the working directory
+func openRepository() (*git.Repository, error) {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return nil, errors.New("Unable to get working directory. " + err.Error())
+	}
+
+	repo, err := git.OpenRepository(pwd)
+	if err != nil {
+		return nil, errors.New("Directory doesn't appear to be a git repository. " + err.Error())
+	}
+
+	return repo, nil
+}
+
+// createTag tags HEAD with v and pushes it if --push is set.
+// With --dry-run it only prints what would be done.
+func createTag(cmd *cobra.Command, repo *git.Repository, previous ver.Version, v ver.Version) error {
+	commit, err := targetCommit(cmd, repo)
+	if err != nil {
+		return err
+	}
+
+	if err := checkRelease(cmd, repo, commit, componentFlag(cmd)); err != nil {
+		return err
 	}
 
-	user, err := ver.GetGitUser()
+	user, err := tagger(cmd, repo)
 	if err != nil {
 		return err
 	}
 
-	commit, err := ver.GetHeadCommit(repo)
+	name := tagFormat.Tag(componentFlag(cmd), v)
+	message, err := tagMessage(cmd, repo, componentFlag(cmd), previous, v, user, commit)
 	if err != nil {
 		return err
 	}
+	pushTags, _ := cmd.Flags().GetBool("push")
+
+	res := newResult(v)
+	res.Component = componentFlag(cmd)
+	res.PreviousVersion = tagVersion(previous)
+	res.Tag = name
+	res.Commit = commit.Id().String()
+
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		logf("Dry run, tag `%s` not created\n", name)
+		logf(" commit:  %s %s\n", commit.Id(), commit.Summary())
+		logf(" tagger:  %s <%s>\n", user.Name, user.Email)
+		if lightweight(cmd) {
+			logf(" type:    lightweight\n")
+		} else {
+			logf(" message: %s\n", strings.Replace(strings.TrimSpace(message), "\n", "\n          ", -1))
+			if s, err := signer(cmd, repo); err != nil {
+				warnf("%s\n", err)
+			} else if s != nil {
+				logf(" signed:  yes\n")
+			}
+		}
+		if pushTags {
+			logf(" push:    refs/tags/%s to %s\n", name, describeRemote(cmd, repo))
+			res.Remote, _ = cmd.Flags().GetString("remote")
+		}
+		res.DryRun = true
+		return printResult(res)
+	}
 
-	id, err := repo.Tags.Create(newVer.String(), commit, user, newVer.String())
+	id, err := newTag(cmd, repo, name, commit, user, message)
 	if err != nil {
 		return errors.New("Unable to create tag. " + err.Error())
 	}
 
-	fmt.Printf("Tag `%s` created successfully\n%s\n", newVer, id)
+	logf("Tag `%s` created successfully\n%s\n", name, id)
+	if !lightweight(cmd) {
+		res.TagId = id.String()
+	}
 
-	if pushTags, _ := cmd.Flags().GetBool("push"); pushTags {
-		// this is fucking embarrassing
-		// please look away
-		push := exec.Command("git", "push", "--tags")
-		err = push.Run()
+	if pushTags {
+		remote, err := pushTag(cmd, repo, name)
 		if err != nil {
 			return err
 		}
+		res.Remote = remote
 	}
 
-	return nil
+	return printResult(res)
+}
+
+// describeRemote returns the name and url of the remote given by --remote
+func describeRemote(cmd *cobra.Command, repo *git.Repository) string {
+	name, _ := cmd.Flags().GetString("remote")
+
+	remote, err := repo.Remotes.Lookup(name)
+	if err != nil {
+		return "`" + name + "` (not found)"
+	}
+	defer remote.Free()
+
+	url := remote.PushUrl()
+	if url == "" {
+		url = remote.Url()
+	}
+
+	return "`" + name + "` (" + url + ")"
+}
+
+// pushTag pushes the tag name to the remote given by --remote
+// and returns the name of the remote
+func pushTag(cmd *cobra.Command, repo *git.Repository, name string) (string, error) {
+	remote, _ := cmd.Flags().GetString("remote")
+	sshKey, _ := cmd.Flags().GetString("ssh-key")
+
+	opts := ver.PushOptions{
+		Remote: remote,
+		SSHKey: sshKey,
+	}
+
+	// the tagged commit of --bump-files only exists locally
+	if bump, _ := cmd.Flags().GetBool("bump-files"); bump {
+		opts.Branch, _ = ver.GetCurrentBranch(repo)
+	}
+
+	err := ver.PushTag(repo, name, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if opts.Branch != "" {
+		logf("Branch `%s` and tag `%s` pushed to `%s`\n", opts.Branch, name, remote)
+	} else {
+		logf("Tag `%s` pushed to `%s`\n", name, remote)
+	}
+
+	return remote, nil
+}
+
+// detectBump infers the increment from the conventional commits since the
+// latest version tag up to target and prints which commit triggered what.
+// latestVer is the current version, which may come from a version file.
+// If path is set only commits changing something below it are considered.
+func detectBump(repo *git.Repository, target *git.Oid, component string, path string, latestVer ver.Version) (ver.BumpKind, error) {
+	tag, err := latestTag(repo, component)
+	if err != nil {
+		return ver.BumpNone, err
+	}
+
+	var since *git.Oid
+	if tag != "" {
+		c, err := ver.GetTagCommit(repo, tag)
+		if err != nil {
+			return ver.BumpNone, err
+		}
+		since = c.Id()
+	}
+
+	commits, err := ver.GetCommitsBetween(repo, since, target)
+	if err != nil {
+		return ver.BumpNone, err
+	}
+
+	if path != "" {
+		all := len(commits)
+		commits, err = ver.FilterCommitsByPath(repo, commits, path)
+		if err != nil {
+			return ver.BumpNone, err
+		}
+		logf("%d of %d commits touch `%s`\n", len(commits), all, path)
+	}
+
+	bumps := make([]ver.CommitBump, 0, len(commits))
+	for _, c := range commits {
+		bumps = append(bumps, ver.CommitBump{
+			Id:      c.Id().String(),
+			Message: c.Message(),
+		})
+	}
+
+	kind, bumps := ver.DetectBump(latestVer, bumps, config.Bump)
+
+	if since != nil {
+		logf("%d commits since `%s`\n", len(bumps), tag)
+	} else {
+		logf("%d commits without a version\n", len(bumps))
+	}
+	for _, b := range bumps {
+		logf(" %-6s %.7s %s (%s)\n", b.Bump, b.Id, b.Summary(), b.Reason)
+	}
+	logf("Bump: %s\n", kind)
 
+	return kind, nil
 }
 
 func init() {
 	RootCmd.PersistentFlags().String("prefix", "v", "Prefix for git tag")
+	RootCmd.PersistentFlags().String("suffix", "", "Suffix for git tag")
+	RootCmd.PersistentFlags().StringSlice("alt-prefixes", []string{}, "Further prefixes accepted when reading tags. e.g. --alt-prefixes '\"\"' to read 1.2.0 next to v1.2.0")
 	RootCmd.PersistentFlags().StringP("set", "s", "", "Set version to this. e.g. ver -s \"v15.8.14\"")
-	RootCmd.PersistentFlags().Bool("push", true, "Set to disable pushing tag to origin")
+	RootCmd.PersistentFlags().Bool("push", true, "Set to disable pushing tag to the remote")
+	RootCmd.PersistentFlags().String("component", "", "Only consider and create tags in this namespace. e.g. ver --component services/api")
+	RootCmd.PersistentFlags().String("remote", "origin", "Remote to push the tag to")
+	RootCmd.PersistentFlags().Bool("dry-run", false, "Print the tag that would be created and pushed without doing it")
+	RootCmd.PersistentFlags().StringP("output", "o", outputText, "Output format. One of text, json, env (VER_OUT_* variables) or plain")
+	RootCmd.PersistentFlags().String("ssh-key", "", "Private ssh key used for pushing. Falls back to the ssh agent")
+	RootCmd.PersistentFlags().String("tagger-name", "", "Name of the tagger. Defaults to GIT_COMMITTER_NAME or user.name of the git config")
+	RootCmd.PersistentFlags().String("tagger-email", "", "Email of the tagger. Defaults to GIT_COMMITTER_EMAIL or user.email of the git config")
+	RootCmd.PersistentFlags().Bool("lightweight", false, "Create lightweight tags, plain refs to the commit without tagger and message")
+	RootCmd.PersistentFlags().Bool("sign", false, "Create signed tags. Defaults to tag.gpgSign of the git config")
+	RootCmd.PersistentFlags().String("signing-key", "", "Key to sign tags with. Defaults to user.signingKey of the git config")
+	RootCmd.PersistentFlags().String("at", "", "Tag this revision instead of HEAD, e.g. HEAD~3, a branch, a short hash or another tag")
+	RootCmd.PersistentFlags().Bool("allow-dirty", false, "Allow tagging with uncommitted changes in the working tree")
+	RootCmd.PersistentFlags().Bool("allow-retag", false, "Allow tagging a commit which already has a version tag")
+	RootCmd.PersistentFlags().Bool("allow-detached", false, "Allow tagging a detached HEAD")
+	RootCmd.PersistentFlags().StringSlice("release-branches", []string{}, "Branches tags may be created on. Supports globs, e.g. main,release/*. Empty allows every branch")
 
 	incrementCmd.Flags().BoolP("major", "M", false, "Increase major version number")
 	incrementCmd.Flags().BoolP("minor", "m", false, "Increase minor version number")
 	incrementCmd.Flags().BoolP("patch", "p", false, "Increase patch version number")
+	incrementCmd.Flags().String("pre", "", "Create the next prerelease in this channel. e.g. ver i -m --pre rc")
+	incrementCmd.Flags().Bool("promote", false, "Promote the latest prerelease to its final release")
+	incrementCmd.Flags().Bool("auto", false, "Infer the increment from conventional commits since the latest version")
+	incrementCmd.Flags().String("branch", "", "Apply the branch rule of this branch instead of the current one. Useful for detached checkouts in CI")
+	incrementCmd.Flags().String("path", "", "Only let commits touching this directory count for --auto. Selects the component living there")
 
 	RootCmd.AddCommand(
 		versionCmd,
@@ -232,6 +556,6 @@ func main() {
 	if err := RootCmd.Execute(); err != nil {
 		// dont print error
 		// it's printed anyways
-		return
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vvvvv/ver/pkg/ver"
+)
+
+var matchCmd = &cobra.Command{
+	Use:   "match <constraint>",
+	Short: "Print the highest version tag satisfying a constraint",
+	Long: "Print the tag of the highest version satisfying a constraint like `>=1.2.0 <2.0.0`, `^1.4`, `~1.4.2`\n" +
+		"or `1.x`. Alternatives are separated by `||`, `1.2 - 1.4` is an inclusive range.\n" +
+		"A bare version like `1.2.3` only matches itself. Prereleases only match if the constraint names\n" +
+		"a prerelease of the same version, e.g. `>=2.0.0-rc.1` matches `2.0.0-rc.2`.",
+	Example: "$ ver match 1.x\n v1.9.2\n$ ver match \"^1.4 || ^2.0.0-rc.1\"\n v2.0.0-rc.3",
+	RunE:    matchCmdFn,
+}
+
+func matchCmdFn(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("Expected exactly one constraint. e.g. ver match \"^1.4\"")
+	}
+
+	constraint, err := ver.ParseConstraint(args[0])
+	if err != nil {
+		return err
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	component := componentFlag(cmd)
+
+	versions, err := ver.GetVersions(repo, component, tagFormat)
+	if err != nil {
+		return err
+	}
+
+	matches := versions.Filter(constraint.Check)
+	if len(matches) == 0 {
+		return fmt.Errorf("No version satisfies `%s`", constraint)
+	}
+
+	v := matches.Max()
+	tag := tagFormat.Tag(component, v)
+
+	if output == outputText {
+		fmt.Printf("%s\n", tag)
+		return nil
+	}
+
+	res := newResult(v)
+	res.Component = component
+	res.Tag = tag
+	if commit, err := ver.GetTagCommit(repo, tag); err == nil {
+		res.Commit = commit.Id().String()
+	}
+
+	return printResult(res)
+}
+
+func init() {
+	RootCmd.AddCommand(matchCmd)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+	"github.com/vvvvv/ver/pkg/ver"
+	git "gopkg.in/libgit2/git2go.v25"
+)
+
+// messageTemplate returns the tag message template given by --message or --message-file
+func messageTemplate(cmd *cobra.Command) (string, error) {
+	if file, _ := cmd.Flags().GetString("message-file"); file != "" {
+		text, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", errors.New("Unable to read tag message template. " + err.Error())
+		}
+		return string(text), nil
+	}
+
+	if text, _ := cmd.Flags().GetString("message"); text != "" {
+		return text, nil
+	}
+
+	return ver.DefaultTagMessage, nil
+}
+
+// tagMessage renders the message of the tag of v in component on commit
+func tagMessage(cmd *cobra.Command, repo *git.Repository, component string, previous ver.Version, v ver.Version, tagger *git.Signature, commit *git.Commit) (string, error) {
+	text, err := messageTemplate(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	data := ver.TagMessageData{
+		Component: component,
+		Previous:  previous,
+		Version:   v,
+		Tag:       tagFormat.Tag(component, v),
+		Author:    tagger.Name,
+		Email:     tagger.Email,
+		Date:      tagger.When,
+	}
+
+	// previous is read from a version file with --source,
+	// the changes start at the latest tag then
+	previousTag := previous.Tag
+	if previousTag == "" {
+		previousTag, err = latestTag(repo, component)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var from *git.Oid
+	if previousTag != "" {
+		c, err := ver.GetTagCommit(repo, previousTag)
+		if err != nil {
+			return "", err
+		}
+		from = c.Id()
+		data.PreviousTag = previousTag
+	}
+
+	data.Changelog, err = changelogSection(repo, tagFormat.Format(v), from, commit.Id())
+	if err != nil {
+		return "", err
+	}
+	data.Changelog.Date = tagger.When
+	data.Commits = data.Changelog.Entries
+
+	return ver.RenderTagMessage(text, data)
+}
+
+func init() {
+	RootCmd.PersistentFlags().String("message", "", "Go text/template for the tag message with .Version, .Previous, .Commits, .Changelog, .Author and .Date. e.g. --message 'Release {{.Version}}'")
+	RootCmd.PersistentFlags().String("message-file", "", "File containing the Go text/template for the tag message")
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vvvvv/ver/pkg/ver"
+)
+
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputEnv   = "env"
+	outputPlain = "plain"
+)
+
+// output is the format given by --output
+var output = outputText
+
+// result is what commands report in the machine readable output formats
+type result struct {
+	Component       string `json:"component,omitempty"`
+	PreviousVersion string `json:"previous_version,omitempty"`
+	Version         string `json:"version"`
+	Major           int    `json:"major"`
+	Minor           int    `json:"minor"`
+	Patch           int    `json:"patch"`
+	Prerelease      string `json:"prerelease"`
+	Metadata        string `json:"metadata"`
+	Tag             string `json:"tag,omitempty"`
+	TagId           string `json:"tag_id,omitempty"`
+	Commit          string `json:"commit,omitempty"`
+	Remote          string `json:"remote,omitempty"`
+	DryRun          bool   `json:"dry_run,omitempty"`
+}
+
+func newResult(v ver.Version) result {
+	return result{
+		Version:    tagVersion(v),
+		Major:      v.Major,
+		Minor:      v.Minor,
+		Patch:      v.Patch,
+		Prerelease: v.Prerelease,
+		Metadata:   v.Metadata,
+	}
+}
+
+// tagVersion returns v as written in the tag it was read from, without
+// the component, so "1.2.3" stays "1.2.3" next to "v1.2.3" tags.
+// Other versions are written in tagFormat.
+func tagVersion(v ver.Version) string {
+	_, name := ver.SplitComponent(tagFormat.Tag("", v))
+	return name
+}
+
+func setOutput(cmd *cobra.Command, args []string) error {
+	output, _ = cmd.Flags().GetString("output")
+	switch output {
+	case outputText, outputJSON, outputEnv, outputPlain:
+		return nil
+	}
+	return errors.New("Unknown output format `" + output + "`. Use one of text, json, env or plain")
+}
+
+// logf prints human readable messages.
+// They go to stderr as soon as stdout is reserved for a machine readable format.
+func logf(format string, a ...interface{}) {
+	if output == outputText {
+		fmt.Printf(format, a...)
+		return
+	}
+	fmt.Fprintf(os.Stderr, format, a...)
+}
+
+// warned are the warnings printed so far
+var warned = map[string]bool{}
+
+// warnf prints a warning to stderr, each one only once
+func warnf(format string, a ...interface{}) {
+	message := fmt.Sprintf(format, a...)
+	if warned[message] {
+		return
+	}
+	warned[message] = true
+
+	fmt.Fprint(os.Stderr, "Warning: "+message)
+}
+
+// printResult prints r in the format given by --output.
+// Nothing is printed for text, which is covered by logf.
+func printResult(r result) error {
+	switch output {
+	case outputJSON:
+		return printJSON(r)
+	case outputEnv:
+		printEnv("COMPONENT", r.Component)
+		printEnv("PREVIOUS_VERSION", r.PreviousVersion)
+		printEnv("VERSION", r.Version)
+		printEnv("MAJOR", strconv.Itoa(r.Major))
+		printEnv("MINOR", strconv.Itoa(r.Minor))
+		printEnv("PATCH", strconv.Itoa(r.Patch))
+		printEnv("PRERELEASE", r.Prerelease)
+		printEnv("METADATA", r.Metadata)
+		printEnv("TAG", r.Tag)
+		printEnv("TAG_ID", r.TagId)
+		printEnv("COMMIT", r.Commit)
+		printEnv("REMOTE", r.Remote)
+		printEnv("DRY_RUN", strconv.FormatBool(r.DryRun))
+	case outputPlain:
+		fmt.Println(r.Version)
+	}
+	return nil
+}
+
+// printJSON prints v as indented JSON
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printEnv prints a single KEY=value line. The VER_OUT_ prefix keeps the
+// variables apart from the VER_* settings read by applyConfig.
+// Values are only quoted if the shell requires it.
+func printEnv(key, value string) {
+	safe := strings.IndexFunc(value, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || strings.ContainsRune("-+._/:@", r))
+	}) < 0
+	if !safe {
+		value = "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+	}
+	fmt.Printf("VER_OUT_%s=%s\n", key, value)
+}
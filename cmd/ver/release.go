@@ -0,0 +1,274 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vvvvv/ver/pkg/ver"
+	git "gopkg.in/libgit2/git2go.v25"
+)
+
+var releaseCmd = &cobra.Command{
+	Use:   "release [component...]",
+	Short: "Release several components at once",
+	Long: "Release the given components, or every component with unreleased changes.\n" +
+		"With --cascade every component depending on a released one gets a patch release as well.\n" +
+		"All tags are created on HEAD, or the commit given by --at. If creating or pushing one of them fails, the created tags are removed again,\n" +
+		"locally and from the remote if it accepted some of them before the push failed.",
+	Example: "$ ver release libs/core -m --cascade\n Release plan\n  libs/core     v0.4.1 -> v0.5.0 (minor, requested)\n  services/api  v1.2.0 -> v1.2.1 (patch, depends on libs/core)",
+	RunE:    releaseCmdFn,
+}
+
+// releaseStep is a single component release in a release plan
+type releaseStep struct {
+	component string
+	previous  ver.Version
+	version   ver.Version
+	bump      ver.BumpKind
+	reason    string
+}
+
+func (s releaseStep) tag() string {
+	return tagFormat.Tag(s.component, s.version)
+}
+
+func releaseCmdFn(cmd *cobra.Command, args []string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	major, _ := cmd.Flags().GetBool("major")
+	minor, _ := cmd.Flags().GetBool("minor")
+	patch, _ := cmd.Flags().GetBool("patch")
+	auto, _ := cmd.Flags().GetBool("auto")
+	cascade, _ := cmd.Flags().GetBool("cascade")
+
+	requested := ver.BumpNone
+	switch {
+	case major:
+		requested = ver.BumpMajor
+	case minor:
+		requested = ver.BumpMinor
+	case patch:
+		requested = ver.BumpPatch
+	}
+	if (requested == ver.BumpNone) == !auto {
+		return errors.New("Use exactly one of -M, -m, -p or --auto")
+	}
+
+	roots := []string{}
+	for _, arg := range args {
+		roots = append(roots, ver.NormalizeComponent(arg))
+	}
+	if len(roots) == 0 {
+		changed, err := changedComponents(repo)
+		if err != nil {
+			return err
+		}
+		for _, c := range changed {
+			roots = append(roots, c.Name)
+		}
+	}
+	if len(roots) == 0 {
+		logf("No component has unreleased changes\n")
+		return nil
+	}
+
+	order, err := ver.ReleaseOrder(config.Components, roots, cascade)
+	if err != nil {
+		return err
+	}
+
+	commit, err := targetCommit(cmd, repo)
+	if err != nil {
+		return err
+	}
+
+	steps, err := releasePlan(repo, commit.Id(), order, roots, requested)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		logf("Nothing to release\n")
+		return nil
+	}
+
+	components := []string{}
+	for _, s := range steps {
+		components = append(components, s.component)
+	}
+	if err := checkRelease(cmd, repo, commit, components...); err != nil {
+		return err
+	}
+
+	logf("Release plan\n")
+	for _, s := range steps {
+		logf(" %-30s %s -> %s (%s, %s)\n", s.component, tagVersion(s.previous), tagFormat.Format(s.version), s.bump, s.reason)
+	}
+
+	results := []result{}
+	for _, s := range steps {
+		res := newResult(s.version)
+		res.Component = s.component
+		res.PreviousVersion = tagVersion(s.previous)
+		res.Tag = s.tag()
+		results = append(results, res)
+	}
+
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		logf("Dry run, no tags created\n")
+		for i := range results {
+			results[i].DryRun = true
+		}
+		return printResults(results)
+	}
+
+	user, err := tagger(cmd, repo)
+	if err != nil {
+		return err
+	}
+
+	created := []string{}
+	for i, s := range steps {
+		message, err := tagMessage(cmd, repo, s.component, s.previous, s.version, user, commit)
+		if err != nil {
+			rollbackTags(repo, created)
+			return err
+		}
+
+		id, err := newTag(cmd, repo, s.tag(), commit, user, message)
+		if err != nil {
+			rollbackTags(repo, created)
+			return errors.New("Unable to create tag `" + s.tag() + "`. " + err.Error())
+		}
+
+		created = append(created, s.tag())
+		if !lightweight(cmd) {
+			results[i].TagId = id.String()
+		}
+		results[i].Commit = commit.Id().String()
+		logf("Tag `%s` created successfully\n", s.tag())
+	}
+
+	if pushTags, _ := cmd.Flags().GetBool("push"); pushTags {
+		remote, _ := cmd.Flags().GetString("remote")
+		sshKey, _ := cmd.Flags().GetString("ssh-key")
+
+		err := ver.PushRelease(repo, created, ver.PushOptions{
+			Remote: remote,
+			SSHKey: sshKey,
+		})
+		if err != nil {
+			return err
+		}
+
+		logf("Tags pushed to `%s`\n", remote)
+		for i := range results {
+			results[i].Remote = remote
+		}
+	}
+
+	return printResults(results)
+}
+
+// releasePlan computes the new version of every component in order.
+// Roots get the requested increment or the one inferred from their commits,
+// every other component a patch release if something it depends on is released.
+func releasePlan(repo *git.Repository, target *git.Oid, order []string, roots []string, requested ver.BumpKind) ([]releaseStep, error) {
+	planned := map[string]bool{}
+	steps := []releaseStep{}
+
+	for _, name := range order {
+		versions, err := ver.GetVersions(repo, name, tagFormat)
+		if err != nil {
+			return nil, err
+		}
+		latest := versions.Latest()
+
+		c, ok := config.Component(name)
+		if !ok {
+			c = ver.Component{Name: name}
+		}
+
+		kind := ver.BumpNone
+		reason := ""
+		if containsString(roots, name) {
+			kind = requested
+			reason = "requested"
+			if requested == ver.BumpNone {
+				logf("%s:\n", name)
+				kind, err = detectBump(repo, target, name, c.Dir(), latest)
+				if err != nil {
+					return nil, err
+				}
+				reason = "commits"
+			}
+		}
+
+		for _, dep := range c.Depends {
+			dep = ver.NormalizeComponent(dep)
+			if planned[dep] && kind < ver.BumpPatch {
+				kind = ver.BumpPatch
+				reason = "depends on " + dep
+			}
+		}
+
+		if kind == ver.BumpNone {
+			continue
+		}
+
+		planned[name] = true
+		steps = append(steps, releaseStep{
+			component: name,
+			previous:  latest,
+			version:   latest.Bump(kind),
+			bump:      kind,
+			reason:    reason,
+		})
+	}
+
+	return steps, nil
+}
+
+// rollbackTags removes the tags created so far
+func rollbackTags(repo *git.Repository, names []string) {
+	for _, name := range names {
+		if err := repo.Tags.Remove(name); err != nil {
+			logf("Unable to remove tag `%s`. %s\n", name, err)
+			continue
+		}
+		logf("Tag `%s` removed\n", name)
+	}
+}
+
+// printResults prints the results of several tags in the format given by --output
+func printResults(results []result) error {
+	switch output {
+	case outputJSON:
+		return printJSON(results)
+	case outputEnv:
+		tags := []string{}
+		for _, r := range results {
+			tags = append(tags, r.Tag)
+		}
+		printEnv("TAGS", strings.Join(tags, " "))
+	case outputPlain:
+		for _, r := range results {
+			fmt.Println(r.Tag)
+		}
+	}
+	return nil
+}
+
+func init() {
+	releaseCmd.Flags().BoolP("major", "M", false, "Increase the major version number of the given components")
+	releaseCmd.Flags().BoolP("minor", "m", false, "Increase the minor version number of the given components")
+	releaseCmd.Flags().BoolP("patch", "p", false, "Increase the patch version number of the given components")
+	releaseCmd.Flags().Bool("auto", false, "Infer the increment of each component from conventional commits in its directory")
+	releaseCmd.Flags().Bool("cascade", false, "Release every component depending on a released one as well")
+
+	RootCmd.AddCommand(releaseCmd)
+}
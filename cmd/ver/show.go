@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vvvvv/ver/pkg/ver"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show [version...]",
+	Short: "Show the tags of versions",
+	Long: "Show whether the tags of the given versions, or of all versions, are annotated,\n" +
+		"their tagger, date, message and the commit they point to.",
+	Example: "$ ver show v1.2.0\n tag:       v1.2.0\n annotated: yes\n signed:    no\n tagger:    Jane Doe <jane@example.com>\n date:      2024-03-01T12:00:00+01:00\n commit:    27c1f1234188aa11585334726f8721d9a35038eb",
+	RunE:    showCmdFn,
+}
+
+// tagDetails describes a tag in the output of show
+type tagDetails struct {
+	Tag         string     `json:"tag"`
+	Version     string     `json:"version"`
+	Annotated   bool       `json:"annotated"`
+	Signed      bool       `json:"signed"`
+	TagId       string     `json:"tag_id,omitempty"`
+	TaggerName  string     `json:"tagger_name,omitempty"`
+	TaggerEmail string     `json:"tagger_email,omitempty"`
+	Date        *time.Time `json:"date,omitempty"`
+	Message     string     `json:"message,omitempty"`
+	Commit      string     `json:"commit"`
+	Summary     string     `json:"summary"`
+}
+
+func showCmdFn(cmd *cobra.Command, args []string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	infos, err := ver.GetVersionTags(repo, componentFlag(cmd), tagFormat)
+	if err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		selected := []ver.TagInfo{}
+		for _, arg := range args {
+			v, err := parseVersion(arg)
+			if err != nil {
+				return fmt.Errorf("Couldn't get version from `%s`. %s", arg, err)
+			}
+
+			found := false
+			for _, info := range infos {
+				if info.Version.Equal(v) {
+					selected = append(selected, info)
+					found = true
+				}
+			}
+			if !found {
+				return fmt.Errorf("Version `%s` doesn't exist", tagFormat.Format(v))
+			}
+		}
+		infos = selected
+	}
+
+	details := []tagDetails{}
+	for _, info := range infos {
+		d := tagDetails{
+			Tag:       info.Name,
+			Version:   tagVersion(info.Version),
+			Annotated: info.Annotated,
+			Signed:    info.Signed,
+			Message:   strings.TrimSpace(info.Message),
+			Commit:    info.Commit.Id().String(),
+			Summary:   info.Commit.Summary(),
+		}
+		if info.Annotated {
+			d.TagId = info.TagId.String()
+			d.TaggerName = info.Tagger.Name
+			d.TaggerEmail = info.Tagger.Email
+			d.Date = &info.Tagger.When
+		}
+		details = append(details, d)
+	}
+
+	switch output {
+	case outputJSON:
+		return printJSON(details)
+	case outputPlain:
+		for _, d := range details {
+			fmt.Println(d.Tag)
+		}
+		return nil
+	}
+
+	for i, d := range details {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("tag:       %s\n", d.Tag)
+		if !d.Annotated {
+			fmt.Printf("annotated: no\n")
+		} else {
+			fmt.Printf("annotated: yes\n")
+			fmt.Printf("signed:    %s\n", yesNo(d.Signed))
+			fmt.Printf("tagger:    %s <%s>\n", d.TaggerName, d.TaggerEmail)
+			fmt.Printf("date:      %s\n", d.Date.Format(time.RFC3339))
+		}
+		fmt.Printf("commit:    %s %s\n", d.Commit, d.Summary)
+		if d.Message != "" {
+			fmt.Printf("message:   %s\n", strings.Replace(d.Message, "\n", "\n           ", -1))
+		}
+	}
+
+	return nil
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func init() {
+	RootCmd.AddCommand(showCmd)
+}
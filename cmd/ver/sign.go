@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vvvvv/ver/pkg/ver"
+	git "gopkg.in/libgit2/git2go.v25"
+)
+
+// signer returns the signer to sign new tags with, or nil for unsigned tags.
+// Without --sign, tag.gpgSign of the git config decides.
+func signer(cmd *cobra.Command, repo *git.Repository) (ver.Signer, error) {
+	sc, err := ver.GetSigningConfig(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	sign := sc.Sign
+	if cmd.Flags().Changed("sign") {
+		sign, _ = cmd.Flags().GetBool("sign")
+	}
+	if !sign {
+		return nil, nil
+	}
+
+	if key, _ := cmd.Flags().GetString("signing-key"); key != "" {
+		sc.Key = key
+	}
+
+	return sc.Signer()
+}
+
+// lightweight reports whether plain refs are created instead of tag objects
+func lightweight(cmd *cobra.Command) bool {
+	lightweight, _ := cmd.Flags().GetBool("lightweight")
+	return lightweight
+}
+
+// tagSigner returns the signer of new tags like signer,
+// but fails if --lightweight and --sign are combined
+func tagSigner(cmd *cobra.Command, repo *git.Repository) (ver.Signer, error) {
+	if lightweight(cmd) {
+		if sign, _ := cmd.Flags().GetBool("sign"); sign {
+			return nil, errors.New("Lightweight tags can't be signed")
+		}
+		return nil, nil
+	}
+
+	return signer(cmd, repo)
+}
+
+// newTag creates the annotated tag name, signed if requested,
+// or a lightweight tag with --lightweight
+func newTag(cmd *cobra.Command, repo *git.Repository, name string, commit *git.Commit, tagger *git.Signature, message string) (*git.Oid, error) {
+	s, err := tagSigner(cmd, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if lightweight(cmd) {
+		return repo.Tags.CreateLightweight(name, commit, false)
+	}
+
+	return ver.CreateTag(repo, name, commit, tagger, message, s)
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [tag...]",
+	Short: "Verify the signatures of version tags",
+	Long: "Verify the signatures of the given tags, or of all version tags.\n" +
+		"OpenPGP signatures are checked with gpg.program, SSH signatures against gpg.ssh.allowedSignersFile.",
+	RunE: verifyCmdFn,
+}
+
+// verifyInfo describes a tag in the output of verify
+type verifyInfo struct {
+	Tag    string `json:"tag"`
+	Valid  bool   `json:"valid"`
+	Format string `json:"format,omitempty"`
+	Signer string `json:"signer,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func verifyCmdFn(cmd *cobra.Command, args []string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	tags := args
+	if len(tags) == 0 {
+		versions, err := ver.GetVersions(repo, componentFlag(cmd), tagFormat)
+		if err != nil {
+			return err
+		}
+		for _, v := range versions.Sorted() {
+			tags = append(tags, tagFormat.Tag(componentFlag(cmd), v))
+		}
+	}
+
+	sc, err := ver.GetSigningConfig(repo)
+	if err != nil {
+		return err
+	}
+	verifiers := sc.Verifiers()
+
+	infos := []verifyInfo{}
+	invalid := 0
+	for _, tag := range tags {
+		info := verifyInfo{Tag: tag}
+
+		sig, err := ver.VerifyTag(repo, tag, verifiers)
+		info.Format = sig.Format
+		if err != nil {
+			info.Error = err.Error()
+			invalid++
+		} else {
+			info.Valid = true
+			info.Signer = sig.Signer
+		}
+
+		infos = append(infos, info)
+	}
+
+	switch output {
+	case outputJSON:
+		if err := printJSON(infos); err != nil {
+			return err
+		}
+	default:
+		for _, info := range infos {
+			if info.Valid {
+				fmt.Printf("%-30s good %s signature by %s\n", info.Tag, info.Format, info.Signer)
+			} else {
+				fmt.Printf("%-30s %s\n", info.Tag, info.Error)
+			}
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d tags have no valid signature", invalid, len(tags))
+	}
+
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(verifyCmd)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/spf13/cobra"
+	"github.com/vvvvv/ver/pkg/ver"
+	git "gopkg.in/libgit2/git2go.v25"
+)
+
+// versionSource returns the source given by --source,
+// the tags of the component or a version file
+func versionSource(cmd *cobra.Command, repo *git.Repository) ver.VersionSource {
+	source, _ := cmd.Flags().GetString("source")
+	if source == "" || source == ver.SourceTags {
+		return ver.TagSource{Repo: repo, Component: componentFlag(cmd), Format: tagFormat}
+	}
+
+	return ver.FileSource{Dir: repo.Workdir(), File: versionFile(cmd, source)}
+}
+
+// versionFile returns the version file p in the directory of the component
+// with the pattern configured for it
+func versionFile(cmd *cobra.Command, p string) ver.VersionFile {
+	f := ver.VersionFile{Path: path.Join(componentDir(cmd), p)}
+	for _, configured := range config.Files {
+		if path.Clean(configured.Path) == path.Clean(p) {
+			f.Pattern = configured.Pattern
+		}
+	}
+	return f
+}
+
+// currentVersions returns the versions of the source given by --source
+func currentVersions(cmd *cobra.Command, repo *git.Repository) (ver.Versions, error) {
+	return versionSource(cmd, repo).Versions()
+}
+
+// latestTag returns the tag of the latest version of component, empty if
+// there is none. Commit ranges start there whatever --source says, since a
+// version read from a file doesn't mark a commit.
+func latestTag(repo *git.Repository, component string) (string, error) {
+	versions, err := ver.GetVersions(repo, component, tagFormat)
+	if err != nil || len(versions) == 0 {
+		return "", err
+	}
+
+	return tagFormat.Tag(component, versions.Latest()), nil
+}
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check that the version files agree with the latest tag",
+	Long: "Check that the version in the file given by --source, or in every version file,\n" +
+		"is the version of the latest tag. Fails if one of them disagrees.",
+	Example: "$ ver check --source VERSION\n VERSION  1.3.0  v1.2.0  mismatch\n Error: 1 of 1 version files disagree with the latest tag `v1.2.0`",
+	RunE:    checkCmdFn,
+}
+
+// checkInfo describes a version file in the output of check
+type checkInfo struct {
+	File    string `json:"file"`
+	Version string `json:"version"`
+	Tag     string `json:"tag"`
+	Match   bool   `json:"match"`
+}
+
+func checkCmdFn(cmd *cobra.Command, args []string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+
+	tags, err := ver.TagSource{Repo: repo, Component: componentFlag(cmd), Format: tagFormat}.Versions()
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return errors.New("No version tags found")
+	}
+	latest := tags.Latest()
+	tag := tagFormat.Tag(componentFlag(cmd), latest)
+
+	files := []ver.VersionFile{}
+	if source, _ := cmd.Flags().GetString("source"); source != "" && source != ver.SourceTags {
+		files = append(files, versionFile(cmd, source))
+	} else {
+		files, err = versionFiles(cmd, repo)
+		if err != nil {
+			return err
+		}
+	}
+
+	infos := []checkInfo{}
+	mismatches := 0
+	for _, f := range files {
+		versions, err := ver.FileSource{Dir: repo.Workdir(), File: f}.Versions()
+		if err != nil {
+			return err
+		}
+
+		v := versions.Latest()
+		info := checkInfo{
+			File:    f.Path,
+			Version: v.String(),
+			Tag:     tag,
+			Match:   v.Equal(latest),
+		}
+		if !info.Match {
+			mismatches++
+		}
+		infos = append(infos, info)
+	}
+
+	if output == outputJSON {
+		if err := printJSON(infos); err != nil {
+			return err
+		}
+	} else {
+		for _, info := range infos {
+			status := "ok"
+			if !info.Match {
+				status = "mismatch"
+			}
+			fmt.Printf("%-30s %-15s %-15s %s\n", info.File, info.Version, info.Tag, status)
+		}
+	}
+
+	if mismatches > 0 {
+		return fmt.Errorf("%d of %d version files disagree with the latest tag `%s`", mismatches, len(files), tag)
+	}
+
+	return nil
+}
+
+func init() {
+	RootCmd.PersistentFlags().String("source", ver.SourceTags, "Where the current version is read from. Either tags or a version file like VERSION or package.json")
+
+	RootCmd.AddCommand(checkCmd)
+}
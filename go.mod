@@ -1,8 +1,10 @@
 module github.com/vvvvv/ver
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/inconshreveable/mousetrap v1.0.0
 	github.com/spf13/cobra v0.0.1
 	github.com/spf13/pflag v1.0.0
 	gopkg.in/libgit2/git2go.v25 v25.0.0-20170120134632-334260d743d7
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,74 @@
+package ver
+
+import (
+	"path"
+	"strings"
+)
+
+// BranchRule decides which versions are released from matching branches,
+// e.g. final releases from `main` and `rc` prereleases from `release/*`.
+type BranchRule struct {
+	// Branch is a shell glob matched against the branch name.
+	Branch string `yaml:"branch" toml:"branch"`
+	// Prerelease is the prerelease channel of versions released from the
+	// branch. `{branch}` is replaced by the slug of the branch name.
+	// Empty means final releases.
+	Prerelease string `yaml:"prerelease" toml:"prerelease"`
+}
+
+// Channel returns the prerelease channel for branch.
+func (r BranchRule) Channel(branch string) string {
+	return strings.Replace(r.Prerelease, "{branch}", BranchSlug(branch), -1)
+}
+
+// MatchBranchRule returns the first of rules matching branch.
+func MatchBranchRule(rules []BranchRule, branch string) (BranchRule, bool) {
+	for _, rule := range rules {
+		if MatchBranch([]string{rule.Branch}, branch) {
+			return rule, true
+		}
+	}
+	return BranchRule{}, false
+}
+
+// MatchBranch reports whether branch matches one of patterns.
+// Patterns are shell globs like `release/*`, where `*` matches `/` as well.
+func MatchBranch(patterns []string, branch string) bool {
+	// hide slashes from path.Match
+	branch = strings.Replace(branch, "/", "\x00", -1)
+	for _, pattern := range patterns {
+		pattern = strings.Replace(pattern, "/", "\x00", -1)
+		if ok, _ := path.Match(pattern, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// BranchSlug turns a branch name into a valid prerelease identifier,
+// e.g. "feature-login-page" for "feature/Login_Page". Numeric slugs get a
+// `b` prefix so a leading zero can't make them invalid, and names without
+// any letter or digit give "branch".
+func BranchSlug(branch string) string {
+	slug := []byte{}
+	dash := false
+	for _, c := range []byte(strings.ToLower(branch)) {
+		if c >= 'a' && c <= 'z' || c >= '0' && c <= '9' {
+			slug = append(slug, c)
+			dash = false
+			continue
+		}
+		if !dash && len(slug) > 0 {
+			slug = append(slug, '-')
+			dash = true
+		}
+	}
+	s := strings.TrimSuffix(string(slug), "-")
+	if s == "" {
+		return "branch"
+	}
+	if strings.Trim(s, "0123456789") == "" {
+		return "b" + s
+	}
+	return s
+}
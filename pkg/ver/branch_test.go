@@ -0,0 +1,58 @@
+package ver
+
+import "testing"
+
+func TestBranchSlug(t *testing.T) {
+	tests := map[string]string{
+		"main":               "main",
+		"feature/Login_Page": "feature-login-page",
+		"feature/007":        "feature-007",
+		"007":                "b007",
+		"release/2024":       "release-2024",
+		"1234":               "b1234",
+		"--fix--":            "fix",
+		"___":                "branch",
+		"":                   "branch",
+	}
+
+	for branch, want := range tests {
+		got := BranchSlug(branch)
+		if got != want {
+			t.Errorf("BranchSlug(%q) = %q, want %q", branch, got, want)
+		}
+		if err := ValidatePrerelease("alpha." + got); err != nil {
+			t.Errorf("BranchSlug(%q) = %q isn't a valid identifier: %s", branch, got, err)
+		}
+	}
+}
+
+func TestBranchRuleChannel(t *testing.T) {
+	rules := []BranchRule{
+		{Branch: "main"},
+		{Branch: "release/*", Prerelease: "rc"},
+		{Branch: "*", Prerelease: "{branch}"},
+	}
+
+	tests := map[string]string{
+		"main":        "",
+		"release/1.x": "rc",
+		"feature/x":   "feature-x",
+		"42":          "b42",
+	}
+
+	for branch, want := range tests {
+		rule, ok := MatchBranchRule(rules, branch)
+		if !ok {
+			t.Errorf("no rule matches %q", branch)
+			continue
+		}
+		if got := rule.Channel(branch); got != want {
+			t.Errorf("channel of %q is %q, want %q", branch, got, want)
+		}
+		if want != "" {
+			if err := ValidatePrerelease(rule.Channel(branch)); err != nil {
+				t.Errorf("channel of %q is invalid: %s", branch, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package ver
+
+import (
+	"strconv"
+	"strings"
+)
+
+// BumpMajor returns v with an incremented major number.
+// Minor and patch are reset, prerelease and metadata are dropped.
+func (v Version) BumpMajor() Version {
+	return Version{Major: v.Major + 1}
+}
+
+// BumpMinor returns v with an incremented minor number.
+// Patch is reset, prerelease and metadata are dropped.
+func (v Version) BumpMinor() Version {
+	return Version{Major: v.Major, Minor: v.Minor + 1}
+}
+
+// BumpPatch returns v with an incremented patch number.
+// Prerelease and metadata are dropped.
+func (v Version) BumpPatch() Version {
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+}
+
+// Release returns the final release of v, i.e. v without
+// prerelease and metadata.
+func (v Version) Release() Version {
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+}
+
+// Channel returns the prerelease without its trailing numeric counter,
+// e.g. "rc" for "1.3.0-rc.2" and "alpha.feature-x" for "1.3.0-alpha.feature-x.1".
+func (v Version) Channel() string {
+	channel, _ := splitPrerelease(v.Prerelease)
+	return channel
+}
+
+// PrereleaseNumber returns the trailing numeric counter of the prerelease,
+// e.g. 2 for "1.3.0-rc.2". It returns 0 if there is none.
+func (v Version) PrereleaseNumber() int {
+	_, n := splitPrerelease(v.Prerelease)
+	return n
+}
+
+func splitPrerelease(pre string) (string, int) {
+	i := strings.LastIndex(pre, ".")
+	if i < 0 {
+		return pre, 0
+	}
+
+	n, err := strconv.Atoi(pre[i+1:])
+	if err != nil || !isNumeric(pre[i+1:]) {
+		return pre, 0
+	}
+
+	return pre[:i], n
+}
+
+// NextPrerelease returns the next prerelease of base in channel.
+// The counter continues after the highest existing prerelease of the same
+// release and channel in versions and starts at 1 otherwise,
+// e.g. "1.3.0-rc.3" if "1.3.0-rc.2" is the highest existing one.
+func (versions Versions) NextPrerelease(base Version, channel string) Version {
+	release := base.Release()
+
+	n := 0
+	for _, v := range versions {
+		if !v.Release().Equal(release) || v.Channel() != channel {
+			continue
+		}
+		if v.PrereleaseNumber() > n {
+			n = v.PrereleaseNumber()
+		}
+	}
+
+	release.Prerelease = channel + "." + strconv.Itoa(n+1)
+	return release
+}
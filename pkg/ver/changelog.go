@@ -0,0 +1,129 @@
+package ver
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ChangelogEntry is a single commit in a changelog.
+type ChangelogEntry struct {
+	Id      string
+	Author  string
+	Message string
+}
+
+// ChangelogSection holds the commits of one release.
+// An empty Title renders as "Unreleased".
+type ChangelogSection struct {
+	Title   string
+	Date    time.Time
+	Entries []ChangelogEntry
+}
+
+// changelog groups in the order they are rendered
+var changelogGroups = []string{
+	"Breaking Changes",
+	"Features",
+	"Bug Fixes",
+	"Other",
+}
+
+func changelogGroup(c *ConventionalCommit) string {
+	switch {
+	case c == nil:
+		return "Other"
+	case c.Breaking:
+		return "Breaking Changes"
+	case c.Type == "feat":
+		return "Features"
+	case c.Type == "fix":
+		return "Bug Fixes"
+	}
+	return "Other"
+}
+
+func (e ChangelogEntry) markdown(c *ConventionalCommit) string {
+	var line string
+	switch {
+	case c == nil:
+		line = strings.SplitN(strings.TrimSpace(e.Message), "\n", 2)[0]
+	case c.Scope != "":
+		line = "**" + c.Scope + ":** " + c.Description
+	default:
+		line = c.Description
+	}
+
+	id := e.Id
+	if len(id) > 7 {
+		id = id[:7]
+	}
+
+	if e.Author != "" {
+		return fmt.Sprintf("- %s (%s, %s)\n", line, id, e.Author)
+	}
+	return fmt.Sprintf("- %s (%s)\n", line, id)
+}
+
+// Markdown renders the section with its commits grouped by
+// Conventional Commit type. Empty groups are left out.
+func (s ChangelogSection) Markdown() string {
+	var buf bytes.Buffer
+
+	title := s.Title
+	if title == "" {
+		title = "Unreleased"
+	}
+	if s.Date.IsZero() {
+		fmt.Fprintf(&buf, "## %s\n", title)
+	} else {
+		fmt.Fprintf(&buf, "## %s (%s)\n", title, s.Date.Format("2006-01-02"))
+	}
+
+	groups := map[string][]string{}
+	for _, e := range s.Entries {
+		c, err := ParseConventionalCommit(e.Message)
+		if err != nil {
+			c = nil
+		}
+		group := changelogGroup(c)
+		groups[group] = append(groups[group], e.markdown(c))
+	}
+
+	for _, group := range changelogGroups {
+		if len(groups[group]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "\n### %s\n\n", group)
+		for _, line := range groups[group] {
+			buf.WriteString(line)
+		}
+	}
+
+	return buf.String()
+}
+
+// RenderChangelog renders sections as Markdown in the given order.
+func RenderChangelog(sections []ChangelogSection) string {
+	parts := make([]string, 0, len(sections))
+	for _, s := range sections {
+		parts = append(parts, s.Markdown())
+	}
+	return strings.Join(parts, "\n")
+}
+
+const changelogHeader = "# Changelog\n"
+
+// PrependChangelog inserts rendered sections into an existing changelog
+// below its "# Changelog" heading. An empty existing changelog gets one.
+func PrependChangelog(existing, rendered string) string {
+	switch {
+	case existing == "":
+		return changelogHeader + "\n" + rendered
+	case strings.HasPrefix(existing, changelogHeader):
+		rest := strings.TrimLeft(strings.TrimPrefix(existing, changelogHeader), "\n")
+		return changelogHeader + "\n" + rendered + "\n" + rest
+	}
+	return rendered + "\n" + existing
+}
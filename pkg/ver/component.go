@@ -0,0 +1,65 @@
+package ver
+
+import (
+	"sort"
+	"strings"
+)
+
+// Component is a part of a monorepo with its own version stream.
+// Its tags live in the namespace Name, e.g. `services/api/v1.2.0`.
+type Component struct {
+	Name string `yaml:"name" toml:"name"`
+	// Path is the directory of the component relative to the repository root.
+	Path string `yaml:"path" toml:"path"`
+	// Depends lists the components this one depends on.
+	// Releasing one of them requires a release of this component as well.
+	Depends []string `yaml:"depends" toml:"depends"`
+}
+
+// Dir returns the directory of the component.
+// Without a configured path it's the namespace itself.
+func (c Component) Dir() string {
+	if c.Path != "" {
+		return strings.Trim(c.Path, "/")
+	}
+	return NormalizeComponent(c.Name)
+}
+
+// SplitComponent splits a tag name into its component namespace and the
+// remaining version part, e.g. "services/api" and "v1.2.0" for
+// "services/api/v1.2.0". Tags without namespace have an empty component.
+func SplitComponent(tag string) (string, string) {
+	i := strings.LastIndex(tag, "/")
+	if i < 0 {
+		return "", tag
+	}
+	return tag[:i], tag[i+1:]
+}
+
+// NormalizeComponent strips surrounding slashes from a component name.
+func NormalizeComponent(component string) string {
+	return strings.Trim(component, "/")
+}
+
+// DiscoverComponents returns the namespaces of all tags which can be parsed
+// as a version in format, sorted. Tags without namespace are left out.
+func DiscoverComponents(tags []string, format TagFormat) []string {
+	seen := map[string]bool{}
+	components := []string{}
+
+	for _, tag := range tags {
+		component, rest := SplitComponent(tag)
+		if component == "" || seen[component] {
+			continue
+		}
+		if _, err := format.Parse(rest); err != nil {
+			continue
+		}
+
+		seen[component] = true
+		components = append(components, component)
+	}
+
+	sort.Strings(components)
+	return components
+}
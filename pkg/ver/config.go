@@ -0,0 +1,175 @@
+package ver
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ConfigFiles are the file names a repository configuration is looked up
+// by, in this order.
+var ConfigFiles = []string{
+	".ver.yaml",
+	".ver.yml",
+	".ver.toml",
+}
+
+// Config is the repository configuration.
+// Top level scalar values are kept in Settings by key,
+// sections are decoded into their own fields.
+type Config struct {
+	// Path is the file the configuration was read from.
+	// It's empty if the repository has none.
+	Path string `yaml:"-" toml:"-"`
+
+	// Settings holds top level scalar values like `prefix: v`.
+	// Lists are joined by "," like the values of list flags,
+	// items which are empty or contain "," or `"` are quoted.
+	Settings map[string]string `yaml:"-" toml:"-"`
+
+	Bump BumpRules `yaml:"bump" toml:"bump"`
+
+	Components []Component `yaml:"components" toml:"components"`
+
+	// Branches are matched against the current branch in order.
+	// The first matching rule decides the prerelease channel.
+	Branches []BranchRule `yaml:"branches" toml:"branches"`
+
+	// Files are the files --bump-files writes the version into.
+	Files []VersionFile `yaml:"files" toml:"files"`
+}
+
+// LoadConfig reads the first of ConfigFiles found in dir.
+// A missing file yields an empty configuration.
+func LoadConfig(dir string) (*Config, error) {
+	for _, name := range ConfigFiles {
+		path := filepath.Join(dir, name)
+
+		data, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.New("Unable to read config. " + err.Error())
+		}
+
+		conf, err := ParseConfig(data, filepath.Ext(name))
+		if err != nil {
+			return nil, errors.New("Unable to parse config `" + path + "`. " + err.Error())
+		}
+		conf.Path = path
+
+		return conf, nil
+	}
+
+	return &Config{Settings: map[string]string{}}, nil
+}
+
+// ParseConfig parses a configuration. ext selects the format and is
+// either ".yaml", ".yml" or ".toml".
+func ParseConfig(data []byte, ext string) (*Config, error) {
+	conf := &Config{Settings: map[string]string{}}
+	raw := map[string]interface{}{}
+
+	switch ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, conf); err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, conf); err != nil {
+			return nil, err
+		}
+		if err := toml.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("Unknown config format `" + ext + "`")
+	}
+
+	for key, value := range raw {
+		switch v := value.(type) {
+		case map[string]interface{}, map[interface{}]interface{}, []map[string]interface{}:
+			// sections are decoded into their own fields
+		case []interface{}:
+			if len(v) > 0 && isSection(v[0]) {
+				continue
+			}
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, csvField(fmt.Sprint(item)))
+			}
+			conf.Settings[key] = strings.Join(items, ",")
+		case nil:
+		default:
+			conf.Settings[key] = fmt.Sprint(v)
+		}
+	}
+
+	return conf, nil
+}
+
+// csvField quotes s if it can't be read back from a comma separated list
+// otherwise, e.g. the empty prefix in `alt-prefixes: [""]`
+func csvField(s string) string {
+	if s != "" && !strings.ContainsAny(s, ",\"\r\n") {
+		return s
+	}
+	return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+}
+
+func isSection(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, map[interface{}]interface{}:
+		return true
+	}
+	return false
+}
+
+// Component returns the configured component name.
+func (c *Config) Component(name string) (Component, bool) {
+	for _, component := range c.Components {
+		if NormalizeComponent(component.Name) == name {
+			return component, true
+		}
+	}
+	return Component{}, false
+}
+
+// ComponentByPath returns the configured component living in path.
+func (c *Config) ComponentByPath(path string) (Component, bool) {
+	path = strings.Trim(path, "/")
+	for _, component := range c.Components {
+		if component.Path != "" && component.Dir() == path {
+			return component, true
+		}
+	}
+	return Component{}, false
+}
+
+// Keys returns the keys of all settings, sorted.
+func (c *Config) Keys() []string {
+	keys := make([]string, 0, len(c.Settings))
+	for key := range c.Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// EnvName returns the environment variable overriding the setting key,
+// e.g. VER_SSH_KEY for ssh-key.
+func EnvName(key string) string {
+	key = strings.NewReplacer("-", "_", ".", "_").Replace(key)
+	return "VER_" + strings.ToUpper(key)
+}
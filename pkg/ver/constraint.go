@@ -0,0 +1,297 @@
+package ver
+
+import (
+	"errors"
+	"strings"
+)
+
+// Constraint is a version range like ">=1.2.0 <2.0.0", "^1.4", "~1.4.2",
+// "1.x" or "1.2.3 - 1.4", combined with "||".
+// The syntax follows npm: comparators separated by spaces (or commas, as in
+// Cargo) must all match, "||" separates alternatives. A bare version is an
+// exact match, a partial one like "1.4" or "1.x" a range.
+//
+// Prereleases only match if a comparator of the same alternative names a
+// prerelease of the same major, minor and patch, e.g. ">=1.4.0-rc.1"
+// matches "1.4.0-rc.2" but not "1.5.0-rc.1", and "^1.4" matches
+// no prerelease at all.
+type Constraint struct {
+	text string
+	// alternatives of comparators which all have to match
+	sets [][]comparator
+}
+
+type comparator struct {
+	op string
+	v  Version
+}
+
+// constraintOperators are ordered so that no operator is a prefix of a later one
+var constraintOperators = []string{">=", "<=", ">", "<", "=", "^", "~"}
+
+// ParseConstraint parses the constraint s.
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{text: strings.TrimSpace(s)}
+
+	for _, alternative := range strings.Split(s, "||") {
+		set, err := parseComparators(alternative)
+		if err != nil {
+			return c, errors.New("Invalid constraint `" + c.text + "`. " + err.Error())
+		}
+		c.sets = append(c.sets, set)
+	}
+
+	return c, nil
+}
+
+// String returns the constraint as it was given.
+func (c Constraint) String() string {
+	return c.text
+}
+
+// Check reports whether v satisfies c.
+func (c Constraint) Check(v Version) bool {
+	for _, set := range c.sets {
+		if matchComparators(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchComparators reports whether v satisfies all comparators of set
+func matchComparators(set []comparator, v Version) bool {
+	for _, c := range set {
+		if !c.match(v) {
+			return false
+		}
+	}
+
+	if !v.IsPrerelease() {
+		return true
+	}
+
+	// prereleases have to be asked for
+	for _, c := range set {
+		if c.v.IsPrerelease() && c.v.Release().Equal(v.Release()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c comparator) match(v Version) bool {
+	n := v.Compare(c.v)
+	switch c.op {
+	case "<":
+		return n < 0
+	case "<=":
+		return n <= 0
+	case ">":
+		return n > 0
+	case ">=":
+		return n >= 0
+	}
+	return n == 0
+}
+
+// parseComparators parses one alternative of a constraint
+// into the comparators it stands for
+func parseComparators(s string) ([]comparator, error) {
+	fields := strings.Fields(strings.Replace(s, ",", " ", -1))
+	if len(fields) == 0 {
+		return nil, errors.New("Empty range")
+	}
+
+	set := []comparator{}
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+
+		// an operator separated from its version
+		if isConstraintOperator(field) {
+			if i+1 == len(fields) {
+				return nil, errors.New("Missing version after `" + field + "`")
+			}
+			i++
+			field += fields[i]
+		}
+
+		// hyphen range
+		if i+1 < len(fields) && fields[i+1] == "-" {
+			if i+2 == len(fields) {
+				return nil, errors.New("Missing upper bound of `" + field + " -`")
+			}
+			from, err := parsePartialVersion(field)
+			if err != nil {
+				return nil, err
+			}
+			to, err := parsePartialVersion(fields[i+2])
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, expandComparator(">=", from)...)
+			set = append(set, expandComparator("<=", to)...)
+			i += 2
+			continue
+		}
+
+		op := ""
+		for _, o := range constraintOperators {
+			if strings.HasPrefix(field, o) {
+				op = o
+				break
+			}
+		}
+
+		p, err := parsePartialVersion(field[len(op):])
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, expandComparator(op, p)...)
+	}
+
+	return set, nil
+}
+
+func isConstraintOperator(s string) bool {
+	for _, o := range constraintOperators {
+		if s == o {
+			return true
+		}
+	}
+	return false
+}
+
+// partialVersion is a version in a constraint, where minor and patch
+// may be missing or wildcards
+type partialVersion struct {
+	Version
+	// parts is the number of given numbers, 0 for "*"
+	parts int
+}
+
+// parsePartialVersion parses versions like "1.2.3-rc.1", "1.2", "1.x" or "*".
+// A leading "v" is ignored, build metadata too.
+func parsePartialVersion(s string) (partialVersion, error) {
+	p := partialVersion{}
+
+	text := strings.TrimPrefix(s, "v")
+	if i := strings.Index(text, "+"); i >= 0 {
+		text = text[:i]
+	}
+	if i := strings.Index(text, "-"); i >= 0 {
+		p.Prerelease = text[i+1:]
+		text = text[:i]
+		if err := ValidatePrerelease(p.Prerelease); err != nil {
+			return p, err
+		}
+	}
+
+	numbers := strings.Split(text, ".")
+	if len(numbers) > 3 {
+		return p, errors.New("Version has more than major, minor and patch. Got `" + s + "`")
+	}
+
+	names := []string{"Major", "Minor", "Patch"}
+	fields := []*int{&p.Major, &p.Minor, &p.Patch}
+	wildcard := false
+	for i, number := range numbers {
+		if number == "x" || number == "X" || number == "*" {
+			wildcard = true
+			continue
+		}
+		if wildcard {
+			return p, errors.New("Wildcards can only be followed by wildcards. Got `" + s + "`")
+		}
+
+		n, err := parseNumber(names[i], number)
+		if err != nil {
+			return p, err
+		}
+		*fields[i] = n
+		p.parts++
+	}
+
+	if p.Prerelease != "" && p.parts < 3 {
+		return p, errors.New("Prereleases need major, minor and patch. Got `" + s + "`")
+	}
+
+	return p, nil
+}
+
+// next returns the lowest version above the range p stands for,
+// e.g. 1.3.0 for 1.2
+func (p partialVersion) next() Version {
+	switch p.parts {
+	case 1:
+		return p.BumpMajor()
+	case 2:
+		return p.BumpMinor()
+	}
+	return p.BumpPatch()
+}
+
+// expandComparator turns an operator and a partial version into
+// plain comparisons. Nothing is returned for ranges matching anything.
+func expandComparator(op string, p partialVersion) []comparator {
+	lower := p.Version
+	nothing := []comparator{{op: "<", v: Version{}}}
+
+	switch op {
+	case "", "=":
+		if p.parts == 0 {
+			return nil
+		}
+		if p.parts == 3 {
+			return []comparator{{op: "=", v: lower}}
+		}
+		return []comparator{{op: ">=", v: lower}, {op: "<", v: p.next()}}
+	case ">":
+		if p.parts == 0 {
+			return nothing
+		}
+		if p.parts == 3 {
+			return []comparator{{op: ">", v: lower}}
+		}
+		return []comparator{{op: ">=", v: p.next()}}
+	case ">=":
+		if p.parts == 0 {
+			return nil
+		}
+		return []comparator{{op: ">=", v: lower}}
+	case "<":
+		if p.parts == 0 {
+			return nothing
+		}
+		return []comparator{{op: "<", v: lower}}
+	case "<=":
+		if p.parts == 0 {
+			return nil
+		}
+		if p.parts == 3 {
+			return []comparator{{op: "<=", v: lower}}
+		}
+		return []comparator{{op: "<", v: p.next()}}
+	case "~":
+		if p.parts == 0 {
+			return nil
+		}
+		if p.parts == 1 {
+			return []comparator{{op: ">=", v: lower}, {op: "<", v: lower.BumpMajor()}}
+		}
+		return []comparator{{op: ">=", v: lower}, {op: "<", v: lower.BumpMinor()}}
+	case "^":
+		// the first non-zero number must not change
+		switch {
+		case p.parts == 0:
+			return nil
+		case p.Major > 0 || p.parts == 1:
+			return []comparator{{op: ">=", v: lower}, {op: "<", v: lower.BumpMajor()}}
+		case p.Minor > 0 || p.parts == 2:
+			return []comparator{{op: ">=", v: lower}, {op: "<", v: lower.BumpMinor()}}
+		}
+		return []comparator{{op: ">=", v: lower}, {op: "<", v: lower.BumpPatch()}}
+	}
+
+	return nothing
+}
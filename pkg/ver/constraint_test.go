@@ -0,0 +1,144 @@
+package ver
+
+import "testing"
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		matching   []string
+		others     []string
+	}{
+		{">=1.2.0 <2.0.0", []string{"1.2.0", "1.9.9"}, []string{"1.1.9", "2.0.0"}},
+		{">= 1.0, < 1.5", []string{"1.0.0", "1.4.9"}, []string{"0.9.0", "1.5.0"}},
+		{"1.2.3", []string{"1.2.3", "1.2.3+build.1"}, []string{"1.2.4", "1.2.2"}},
+		{"=v1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{"*", []string{"0.0.0", "9.9.9"}, nil},
+		{"1.x", []string{"1.0.0", "1.99.3"}, []string{"0.9.0", "2.0.0"}},
+		{"1.2", []string{"1.2.0", "1.2.5"}, []string{"1.1.0", "1.3.0"}},
+		{"1.2.*", []string{"1.2.5"}, []string{"1.3.0"}},
+		{"~1.4.2", []string{"1.4.2", "1.4.9"}, []string{"1.4.1", "1.5.0"}},
+		{"~1.4", []string{"1.4.0", "1.4.9"}, []string{"1.5.0"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{"^1.4", []string{"1.4.0", "1.9.0"}, []string{"1.3.9", "2.0.0"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.9"}, []string{"1.2.2", "2.0.0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4", "0.0.2"}},
+		{"^0.0.x", []string{"0.0.0", "0.0.9"}, []string{"0.1.0"}},
+		{"^0.0", []string{"0.0.9"}, []string{"0.1.0"}},
+		{"^0.x", []string{"0.0.1", "0.9.0"}, []string{"1.0.0"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{">1.2.3", []string{"1.2.4"}, []string{"1.2.3"}},
+		{"<1.2", []string{"1.1.9"}, []string{"1.2.0"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{">*", nil, []string{"0.0.0", "1.0.0"}},
+		{"<*", nil, []string{"0.0.0", "1.0.0"}},
+		{"1.2 - 2.3", []string{"1.2.0", "2.3.9"}, []string{"1.1.9", "2.4.0"}},
+		{"1.2.3 - 2.3.4", []string{"1.2.3", "2.3.4"}, []string{"1.2.2", "2.3.5"}},
+		{"^1.2 || ^3.0", []string{"1.5.0", "3.1.0"}, []string{"2.0.0", "4.0.0"}},
+		{"<1.0.0 || >=2.0.0 <2.1.0", []string{"0.9.0", "2.0.5"}, []string{"1.5.0", "2.1.0"}},
+	}
+
+	for _, test := range tests {
+		c, err := ParseConstraint(test.constraint)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q) failed: %s", test.constraint, err)
+			continue
+		}
+
+		for _, s := range test.matching {
+			if v, _ := Parse(s); !c.Check(*v) {
+				t.Errorf("%q doesn't match %s", test.constraint, s)
+			}
+		}
+		for _, s := range test.others {
+			if v, _ := Parse(s); c.Check(*v) {
+				t.Errorf("%q matches %s", test.constraint, s)
+			}
+		}
+	}
+}
+
+func TestConstraintPrereleases(t *testing.T) {
+	tests := []struct {
+		constraint string
+		matching   []string
+		others     []string
+	}{
+		// no prerelease without asking for one
+		{"^1.4", nil, []string{"1.5.0-rc.1", "2.0.0-rc.1"}},
+		{"1.x", nil, []string{"1.2.0-beta.1"}},
+		{"*", nil, []string{"1.0.0-rc.1"}},
+		{">=1.2.0 <2.0.0", nil, []string{"1.5.0-rc.1", "2.0.0-rc.1"}},
+		// only prereleases of the named version
+		{">=1.4.0-rc.1", []string{"1.4.0-rc.1", "1.4.0-rc.2", "1.4.0", "1.5.0"}, []string{"1.4.0-beta.1", "1.5.0-rc.1"}},
+		{"^1.4.0-rc.1", []string{"1.4.0-rc.2", "1.4.5"}, []string{"1.4.1-rc.1", "2.0.0-rc.1"}},
+		{"~1.4.2-beta.2", []string{"1.4.2-beta.3", "1.4.3"}, []string{"1.4.2-beta.1", "1.4.3-beta.1"}},
+		{"<2.0.0-rc.3", []string{"1.0.0", "2.0.0-rc.2"}, []string{"2.0.0-rc.3", "1.9.0-rc.1"}},
+		{"1.0.0-rc.1 - 1.0.0", []string{"1.0.0-rc.1", "1.0.0-rc.5", "1.0.0"}, []string{"1.0.1"}},
+		// each alternative opts in on its own
+		{"^1.0.0 || 2.0.0-rc.1 - 2.0.0", []string{"1.2.0", "2.0.0-rc.2"}, []string{"1.3.0-rc.1"}},
+	}
+
+	for _, test := range tests {
+		c, err := ParseConstraint(test.constraint)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q) failed: %s", test.constraint, err)
+			continue
+		}
+
+		for _, s := range test.matching {
+			if v, _ := Parse(s); !c.Check(*v) {
+				t.Errorf("%q doesn't match %s", test.constraint, s)
+			}
+		}
+		for _, s := range test.others {
+			if v, _ := Parse(s); c.Check(*v) {
+				t.Errorf("%q matches %s", test.constraint, s)
+			}
+		}
+	}
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"||",
+		"^1.2 ||",
+		">=",
+		"foo",
+		"1.x.3",
+		"1.2-rc.1",
+		"1.2.3.4",
+		">=01.2",
+		"1.2.3-01",
+		"1.2.3 -",
+		">=1 - 2",
+	}
+
+	for _, s := range invalid {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("ParseConstraint(%q) succeeded, want an error", s)
+		}
+	}
+}
+
+func TestConstraintHighest(t *testing.T) {
+	versions := Versions{}
+	for _, tag := range []string{"v1.2.0", "v1.10.1", "v2.0.0", "v1.11.0-rc.1", "v0.9.0"} {
+		v, err := DefaultTagFormat.Parse(tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, v)
+	}
+
+	c, err := ParseConstraint("1.x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matches := versions.Filter(c.Check)
+	if len(matches) != 2 || matches.Max().Tag != "v1.10.1" {
+		t.Errorf("1.x matches %v", matches)
+	}
+}
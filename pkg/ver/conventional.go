@@ -0,0 +1,182 @@
+package ver
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// BumpKind describes which part of a version gets incremented.
+type BumpKind int
+
+const (
+	BumpNone BumpKind = iota
+	BumpPatch
+	BumpMinor
+	BumpMajor
+)
+
+func (k BumpKind) String() string {
+	switch k {
+	case BumpPatch:
+		return "patch"
+	case BumpMinor:
+		return "minor"
+	case BumpMajor:
+		return "major"
+	}
+	return "none"
+}
+
+// Bump returns v incremented by k.
+func (v Version) Bump(k BumpKind) Version {
+	switch k {
+	case BumpMajor:
+		return v.BumpMajor()
+	case BumpMinor:
+		return v.BumpMinor()
+	case BumpPatch:
+		return v.BumpPatch()
+	}
+	return v
+}
+
+// ConventionalCommit is a commit message following
+// https://www.conventionalcommits.org/en/v1.0.0/
+type ConventionalCommit struct {
+	Type        string
+	Scope       string
+	Description string
+	Body        string
+	Breaking    bool
+}
+
+var conventionalHeader = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()]*)\))?(!)?: (.+)$`)
+
+// ParseConventionalCommit parses a commit message.
+// It fails if the header doesn't follow the Conventional Commits format.
+func ParseConventionalCommit(message string) (*ConventionalCommit, error) {
+	lines := strings.SplitN(strings.TrimSpace(message), "\n", 2)
+
+	m := conventionalHeader.FindStringSubmatch(strings.TrimSpace(lines[0]))
+	if m == nil {
+		return nil, errors.New("Not a conventional commit. Got `" + lines[0] + "`")
+	}
+
+	c := &ConventionalCommit{
+		Type:        strings.ToLower(m[1]),
+		Scope:       m[2],
+		Description: m[4],
+		Breaking:    m[3] == "!",
+	}
+
+	if len(lines) == 2 {
+		c.Body = strings.TrimSpace(lines[1])
+	}
+
+	for _, line := range strings.Split(c.Body, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			c.Breaking = true
+		}
+	}
+
+	return c, nil
+}
+
+// BumpRules map Conventional Commit types to the increment they trigger.
+// Breaking changes always bump major.
+type BumpRules struct {
+	Major []string `yaml:"major" toml:"major"`
+	Minor []string `yaml:"minor" toml:"minor"`
+	Patch []string `yaml:"patch" toml:"patch"`
+}
+
+// DefaultBumpRules bump minor for `feat` and patch for `fix` and `perf`.
+var DefaultBumpRules = BumpRules{
+	Minor: []string{"feat"},
+	Patch: []string{"fix", "perf"},
+}
+
+// IsZero reports whether no rule is set at all.
+func (r BumpRules) IsZero() bool {
+	return len(r.Major) == 0 && len(r.Minor) == 0 && len(r.Patch) == 0
+}
+
+// Bump returns the increment c triggers under r.
+func (r BumpRules) Bump(c ConventionalCommit) BumpKind {
+	switch {
+	case c.Breaking || containsString(r.Major, c.Type):
+		return BumpMajor
+	case containsString(r.Minor, c.Type):
+		return BumpMinor
+	case containsString(r.Patch, c.Type):
+		return BumpPatch
+	}
+	return BumpNone
+}
+
+// Bump returns the increment the commit asks for under DefaultBumpRules.
+func (c ConventionalCommit) Bump() BumpKind {
+	return DefaultBumpRules.Bump(c)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// CommitBump records which increment a single commit triggered.
+type CommitBump struct {
+	Id      string
+	Message string
+	Bump    BumpKind
+	Reason  string
+}
+
+// Summary returns the first line of the commit message.
+func (c CommitBump) Summary() string {
+	return strings.SplitN(strings.TrimSpace(c.Message), "\n", 2)[0]
+}
+
+// DetectBump returns the increment commits require on top of latest,
+// along with the increment each commit triggered. Commits that don't follow
+// the Conventional Commits format don't trigger anything.
+// Empty rules fall back to DefaultBumpRules.
+// While the major version is 0, breaking changes only bump minor.
+func DetectBump(latest Version, commits []CommitBump, rules BumpRules) (BumpKind, []CommitBump) {
+	if rules.IsZero() {
+		rules = DefaultBumpRules
+	}
+
+	kind := BumpNone
+	result := make([]CommitBump, 0, len(commits))
+
+	for _, c := range commits {
+		cc, err := ParseConventionalCommit(c.Message)
+		if err != nil {
+			c.Bump = BumpNone
+			c.Reason = "not a conventional commit"
+		} else {
+			c.Bump = rules.Bump(*cc)
+			c.Reason = cc.Type
+			if cc.Breaking {
+				c.Reason = "breaking change"
+			}
+			if c.Bump == BumpMajor && latest.Major == 0 {
+				c.Bump = BumpMinor
+				c.Reason += " before 1.0.0"
+			}
+		}
+
+		if c.Bump > kind {
+			kind = c.Bump
+		}
+		result = append(result, c)
+	}
+
+	return kind, result
+}
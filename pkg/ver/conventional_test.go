@@ -0,0 +1,118 @@
+package ver
+
+import "testing"
+
+func TestParseConventionalCommit(t *testing.T) {
+	tests := []struct {
+		message string
+		want    ConventionalCommit
+	}{
+		{"feat: add login", ConventionalCommit{Type: "feat", Description: "add login"}},
+		{"Fix(api): handle nil\n", ConventionalCommit{Type: "fix", Scope: "api", Description: "handle nil"}},
+		{"feat!: drop v1 api", ConventionalCommit{Type: "feat", Description: "drop v1 api", Breaking: true}},
+		{"refactor(core)!: rename", ConventionalCommit{Type: "refactor", Scope: "core", Description: "rename", Breaking: true}},
+		{
+			"feat: new config\n\nUses yaml now.\n\nBREAKING CHANGE: the ini format is gone",
+			ConventionalCommit{Type: "feat", Description: "new config", Body: "Uses yaml now.\n\nBREAKING CHANGE: the ini format is gone", Breaking: true},
+		},
+		{
+			"fix: typo\n\nBREAKING-CHANGE: none really",
+			ConventionalCommit{Type: "fix", Description: "typo", Body: "BREAKING-CHANGE: none really", Breaking: true},
+		},
+		// only footers count
+		{
+			"fix: typo\n\nThis is not a BREAKING CHANGE: promise\nbreaking change: lowercase",
+			ConventionalCommit{Type: "fix", Description: "typo", Body: "This is not a BREAKING CHANGE: promise\nbreaking change: lowercase"},
+		},
+	}
+
+	for _, test := range tests {
+		c, err := ParseConventionalCommit(test.message)
+		if err != nil {
+			t.Errorf("ParseConventionalCommit(%q) failed: %s", test.message, err)
+			continue
+		}
+		if *c != test.want {
+			t.Errorf("ParseConventionalCommit(%q) = %+v, want %+v", test.message, *c, test.want)
+		}
+	}
+}
+
+func TestParseConventionalCommitInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"Merge branch 'main'",
+		"Update README.md",
+		"feat:missing space",
+		"feat : space before colon",
+		"feat(api: unclosed scope",
+		"feat(a)(b): two scopes",
+		"feat: \n\nno description",
+		"WIP\n\nfeat: in the body",
+	}
+
+	for _, message := range invalid {
+		if c, err := ParseConventionalCommit(message); err == nil {
+			t.Errorf("ParseConventionalCommit(%q) = %+v, want an error", message, *c)
+		}
+	}
+}
+
+func TestDetectBump(t *testing.T) {
+	custom := BumpRules{
+		Major: []string{"epic"},
+		Minor: []string{"feat", "deps"},
+		Patch: []string{"fix", "docs"},
+	}
+
+	tests := []struct {
+		latest   string
+		messages []string
+		rules    BumpRules
+		want     BumpKind
+		bumps    []BumpKind
+	}{
+		{"1.2.3", nil, BumpRules{}, BumpNone, nil},
+		{"1.2.3", []string{"fix: a", "chore: b"}, BumpRules{}, BumpPatch, []BumpKind{BumpPatch, BumpNone}},
+		{"1.2.3", []string{"perf: a", "feat: b", "fix: c"}, BumpRules{}, BumpMinor, []BumpKind{BumpPatch, BumpMinor, BumpPatch}},
+		{"1.2.3", []string{"fix!: a", "feat: b"}, BumpRules{}, BumpMajor, []BumpKind{BumpMajor, BumpMinor}},
+		{"1.2.3", []string{"chore: a\n\nBREAKING CHANGE: b"}, BumpRules{}, BumpMajor, []BumpKind{BumpMajor}},
+		// breaking changes before 1.0.0 only bump minor
+		{"0.4.1", []string{"feat!: a", "fix: b"}, BumpRules{}, BumpMinor, []BumpKind{BumpMinor, BumpPatch}},
+		{"0.4.1", []string{"epic: a"}, custom, BumpMinor, []BumpKind{BumpMinor}},
+		{"1.0.0-rc.1", []string{"feat!: a"}, BumpRules{}, BumpMajor, []BumpKind{BumpMajor}},
+		// non-conventional commits don't trigger anything
+		{"1.2.3", []string{"Merge branch 'main'", "WIP", "docs: readme"}, BumpRules{}, BumpNone, []BumpKind{BumpNone, BumpNone, BumpNone}},
+		// custom rules replace the defaults
+		{"1.2.3", []string{"docs: readme"}, custom, BumpPatch, []BumpKind{BumpPatch}},
+		{"1.2.3", []string{"deps: bump yaml", "perf: faster"}, custom, BumpMinor, []BumpKind{BumpMinor, BumpNone}},
+		{"1.2.3", []string{"epic: a"}, custom, BumpMajor, []BumpKind{BumpMajor}},
+		{"1.2.3", []string{"fix!: a"}, BumpRules{Patch: []string{"fix"}}, BumpMajor, []BumpKind{BumpMajor}},
+	}
+
+	for _, test := range tests {
+		latest, err := Parse(test.latest)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		commits := []CommitBump{}
+		for _, message := range test.messages {
+			commits = append(commits, CommitBump{Message: message})
+		}
+
+		kind, result := DetectBump(*latest, commits, test.rules)
+		if kind != test.want {
+			t.Errorf("DetectBump(%s, %q) = %s, want %s", test.latest, test.messages, kind, test.want)
+		}
+		if len(result) != len(test.bumps) {
+			t.Errorf("DetectBump(%s, %q) returned %d commits", test.latest, test.messages, len(result))
+			continue
+		}
+		for i, c := range result {
+			if c.Bump != test.bumps[i] || c.Reason == "" {
+				t.Errorf("DetectBump(%s, %q) bumps %q by %s (%q), want %s", test.latest, test.messages, c.Message, c.Bump, c.Reason, test.bumps[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package ver
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gitDateLayouts are the RFC 2822 and ISO 8601 formats git accepts in
+// GIT_COMMITTER_DATE, see DATE FORMATS in git-commit(1).
+var gitDateLayouts = []string{
+	time.RFC1123Z,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05-07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// ParseGitDate parses a date in one of the formats of GIT_COMMITTER_DATE,
+// the internal `<unix timestamp> <time zone offset>`, RFC 2822 or ISO 8601.
+// Dates without time zone are local.
+func ParseGitDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+
+	if t, ok := parseRawDate(s); ok {
+		return t, nil
+	}
+
+	for _, layout := range gitDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, errors.New("Unknown date format `" + s + "`")
+}
+
+// parseRawDate parses git's internal format, e.g. "1112911993 +0200"
+func parseRawDate(s string) (time.Time, bool) {
+	fields := strings.Fields(strings.TrimPrefix(s, "@"))
+	if len(fields) == 0 || len(fields) > 2 || !isNumeric(fields[0]) {
+		return time.Time{}, false
+	}
+
+	unix, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	t := time.Unix(unix, 0)
+
+	if len(fields) == 1 {
+		return t.UTC(), true
+	}
+
+	zone, err := time.Parse("-0700", fields[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t.In(zone.Location()), true
+}
@@ -0,0 +1,77 @@
+package ver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseGitDate(t *testing.T) {
+	// 2005-04-07 20:13:13 UTC
+	const unix = 1112904793
+
+	tests := []struct {
+		in     string
+		offset int
+	}{
+		{"1112904793 +0200", 2 * 3600},
+		{"1112904793 -0530", -(5*3600 + 30*60)},
+		{"@1112904793", 0},
+		{"@1112904793 +0200", 2 * 3600},
+		{"  1112904793 +0000\n", 0},
+		{"Thu, 07 Apr 2005 22:13:13 +0200", 2 * 3600},
+		{"Thu, 7 Apr 2005 22:13:13 +0200", 2 * 3600},
+		{"2005-04-07T22:13:13+02:00", 2 * 3600},
+		{"2005-04-07T20:13:13Z", 0},
+		{"2005-04-07T22:13:13+0200", 2 * 3600},
+		{"2005-04-07 22:13:13 +0200", 2 * 3600},
+		{"2005-04-07 15:13:13-05:00", -5 * 3600},
+	}
+
+	for _, test := range tests {
+		got, err := ParseGitDate(test.in)
+		if err != nil {
+			t.Errorf("ParseGitDate(%q) failed: %s", test.in, err)
+			continue
+		}
+		if got.Unix() != unix {
+			t.Errorf("ParseGitDate(%q) = %s, want %s", test.in, got, time.Unix(unix, 0).UTC())
+		}
+		if _, offset := got.Zone(); offset != test.offset {
+			t.Errorf("ParseGitDate(%q) has offset %d, want %d", test.in, offset, test.offset)
+		}
+	}
+}
+
+func TestParseGitDateLocal(t *testing.T) {
+	want := time.Date(2005, 4, 7, 22, 13, 13, 0, time.Local)
+
+	for _, in := range []string{"2005-04-07T22:13:13", "2005-04-07 22:13:13"} {
+		got, err := ParseGitDate(in)
+		if err != nil {
+			t.Errorf("ParseGitDate(%q) failed: %s", in, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("ParseGitDate(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestParseGitDateInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"@",
+		"yesterday",
+		"2005-04-07",
+		"1112904793 +02",
+		"1112904793 +0200 extra",
+		"12abc +0200",
+		"07/04/2005 22:13:13",
+	}
+
+	for _, in := range invalid {
+		if got, err := ParseGitDate(in); err == nil {
+			t.Errorf("ParseGitDate(%q) = %s, want an error", in, got)
+		}
+	}
+}
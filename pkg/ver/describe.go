@@ -0,0 +1,98 @@
+package ver
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Description locates a commit relative to the nearest version tag
+// like `git describe --tags --long` does.
+type Description struct {
+	// Tag is the nearest version tag. It's empty if there is none.
+	Tag string
+	// Version is the version of Tag.
+	Version Version
+	// Distance is the number of commits since Tag.
+	Distance int
+	// Hash is the abbreviated commit id.
+	Hash string
+	// Dirty is set if the working tree has uncommitted changes.
+	Dirty bool
+}
+
+// ParseDescription parses the long format of `git describe`,
+// e.g. "v1.3.0-7-g27c1f12", with tags in format. A plain abbreviated
+// commit id is a commit without any tag before it.
+func ParseDescription(s string, format TagFormat) (Description, error) {
+	d := Description{}
+
+	i := strings.LastIndex(s, "-g")
+	if i < 0 {
+		if !isAlphanumeric(s) {
+			return d, errors.New("Invalid description `" + s + "`")
+		}
+		d.Hash = s
+		return d, nil
+	}
+	d.Hash = s[i+2:]
+
+	j := strings.LastIndex(s[:i], "-")
+	if j < 0 {
+		return d, errors.New("Invalid description `" + s + "`")
+	}
+
+	distance, err := strconv.Atoi(s[j+1 : i])
+	if err != nil {
+		return d, errors.New("Invalid distance in description `" + s + "`")
+	}
+	d.Distance = distance
+	d.Tag = s[:j]
+
+	d.Version, err = format.Parse(d.Tag)
+	if err != nil {
+		return d, err
+	}
+
+	return d, nil
+}
+
+// DevVersion returns a development version for the described commit,
+// e.g. "1.4.1-dev.7+g27c1f12" for a commit 7 commits after "1.4.0".
+// Final versions get the increment kind first, prereleases get the channel
+// appended, e.g. "1.4.0-rc.1.dev.7+g27c1f12", so the development version
+// always sorts after the tag it's based on.
+// A clean commit with a tag is described by the tagged version itself.
+func (d Description) DevVersion(channel string, kind BumpKind) Version {
+	if d.Tag != "" && d.Distance == 0 && !d.Dirty {
+		return d.Version
+	}
+
+	v := d.Version
+	v.Tag = ""
+	if v.IsPrerelease() {
+		v.Prerelease += "." + channel + "." + strconv.Itoa(d.Distance)
+	} else {
+		v = v.Bump(kind)
+		v.Prerelease = channel + "." + strconv.Itoa(d.Distance)
+	}
+
+	v.Metadata = "g" + d.Hash
+	if d.Dirty {
+		v.Metadata += ".dirty"
+	}
+
+	return v
+}
+
+func isAlphanumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
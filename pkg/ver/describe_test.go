@@ -0,0 +1,98 @@
+package ver
+
+import "testing"
+
+func TestParseDescription(t *testing.T) {
+	tests := []struct {
+		in       string
+		tag      string
+		version  string
+		distance int
+		hash     string
+	}{
+		{"v1.3.0-7-g27c1f12", "v1.3.0", "1.3.0", 7, "27c1f12"},
+		{"v1.3.0-0-g27c1f12", "v1.3.0", "1.3.0", 0, "27c1f12"},
+		// tags containing -g
+		{"v1.3.0-gamma-2-g27c1f12", "v1.3.0-gamma", "1.3.0-gamma", 2, "27c1f12"},
+		{"v2.0.0-rc.1-g-12-gabc1234", "v2.0.0-rc.1-g", "2.0.0-rc.1-g", 12, "abc1234"},
+		{"services/api/v0.4.1-3-g27c1f12", "services/api/v0.4.1", "0.4.1", 3, "27c1f12"},
+		// no tag at all
+		{"27c1f12", "", "0.0.0", 0, "27c1f12"},
+	}
+
+	for _, test := range tests {
+		d, err := ParseDescription(test.in, DefaultTagFormat)
+		if err != nil {
+			t.Errorf("ParseDescription(%q) failed: %s", test.in, err)
+			continue
+		}
+		if d.Tag != test.tag || d.Version.String() != test.version || d.Distance != test.distance || d.Hash != test.hash {
+			t.Errorf("ParseDescription(%q) = %+v", test.in, d)
+		}
+		if d.Tag != "" && d.Version.Tag != test.tag {
+			t.Errorf("ParseDescription(%q) keeps tag %q in the version", test.in, d.Version.Tag)
+		}
+	}
+}
+
+func TestParseDescriptionInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"27c1f12-dirty",
+		"v1.3.0-g27c1f12",
+		"v1.3.0-x-g27c1f12",
+		"release-7-g27c1f12",
+		"1.3.0-7-g27c1f12",
+	}
+
+	for _, in := range invalid {
+		if d, err := ParseDescription(in, DefaultTagFormat); err == nil {
+			t.Errorf("ParseDescription(%q) = %+v, want an error", in, d)
+		}
+	}
+}
+
+func TestDevVersion(t *testing.T) {
+	tagged := func(tag string) Version {
+		v, err := DefaultTagFormat.Parse(tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		d       Description
+		channel string
+		kind    BumpKind
+		want    string
+	}{
+		{Description{Tag: "v1.4.0", Version: tagged("v1.4.0"), Distance: 7, Hash: "27c1f12"}, "dev", BumpPatch, "1.4.1-dev.7+g27c1f12"},
+		{Description{Tag: "v1.4.0", Version: tagged("v1.4.0"), Distance: 7, Hash: "27c1f12"}, "nightly", BumpMinor, "1.5.0-nightly.7+g27c1f12"},
+		{Description{Tag: "v1.4.0", Version: tagged("v1.4.0"), Distance: 7, Hash: "27c1f12"}, "dev", BumpMajor, "2.0.0-dev.7+g27c1f12"},
+		// prereleases keep their base and get the channel appended
+		{Description{Tag: "v1.4.0-rc.1", Version: tagged("v1.4.0-rc.1"), Distance: 3, Hash: "27c1f12"}, "dev", BumpPatch, "1.4.0-rc.1.dev.3+g27c1f12"},
+		{Description{Tag: "v1.4.0-rc.1", Version: tagged("v1.4.0-rc.1"), Distance: 3, Hash: "27c1f12"}, "dev", BumpMajor, "1.4.0-rc.1.dev.3+g27c1f12"},
+		// uncommitted changes
+		{Description{Tag: "v1.4.0", Version: tagged("v1.4.0"), Distance: 7, Hash: "27c1f12", Dirty: true}, "dev", BumpPatch, "1.4.1-dev.7+g27c1f12.dirty"},
+		{Description{Tag: "v1.4.0", Version: tagged("v1.4.0"), Distance: 0, Hash: "27c1f12", Dirty: true}, "dev", BumpPatch, "1.4.1-dev.0+g27c1f12.dirty"},
+		// a clean tagged commit is the tagged version
+		{Description{Tag: "v1.4.0", Version: tagged("v1.4.0"), Distance: 0, Hash: "27c1f12"}, "dev", BumpPatch, "1.4.0"},
+		// no tag before the commit
+		{Description{Distance: 5, Hash: "27c1f12"}, "dev", BumpPatch, "0.0.1-dev.5+g27c1f12"},
+		{Description{Distance: 0, Hash: "27c1f12"}, "dev", BumpMinor, "0.1.0-dev.0+g27c1f12"},
+	}
+
+	for _, test := range tests {
+		v := test.d.DevVersion(test.channel, test.kind)
+		if v.String() != test.want {
+			t.Errorf("DevVersion(%+v, %s, %s) = %s, want %s", test.d, test.channel, test.kind, v, test.want)
+		}
+		if _, err := Parse(v.String()); err != nil {
+			t.Errorf("DevVersion(%+v) = %s isn't valid. %s", test.d, v, err)
+		}
+		if v.String() != test.d.Version.String() && !v.GreaterThan(test.d.Version) {
+			t.Errorf("DevVersion(%+v) = %s sorts before %s", test.d, v, test.d.Version)
+		}
+	}
+}
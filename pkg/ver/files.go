@@ -0,0 +1,170 @@
+package ver
+
+import (
+	"errors"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// VersionFile is a file the version is written into.
+type VersionFile struct {
+	// Path is relative to the repository root, or to the component's directory.
+	Path string `yaml:"path" toml:"path"`
+	// Pattern is a regular expression whose first group is replaced by the
+	// version. It's only needed for files DefaultVersionFiles doesn't know.
+	Pattern string `yaml:"pattern" toml:"pattern"`
+}
+
+// DefaultVersionFiles are the files the version is read from and written
+// into if they exist and no files are configured.
+var DefaultVersionFiles = []string{
+	"VERSION",
+	"package.json",
+	"Cargo.toml",
+	"pyproject.toml",
+	"Chart.yaml",
+	"version.go",
+}
+
+var (
+	plainVersion = regexp.MustCompile(`^\s*(\S+)`)
+	chartVersion = regexp.MustCompile(`(?m)^version:[ \t]*["']?([^"'\s#]+)`)
+	goVersion    = regexp.MustCompile(`(?m)^\s*(?:const\s+)?Version\s*(?:string\s*)?=\s*"([^"]*)"`)
+
+	jsonValue = regexp.MustCompile(`^\s*:\s*"([^"\\]*)"`)
+
+	tomlSection = regexp.MustCompile(`^\s*\[([^\[\]]+)\]\s*(#.*)?$`)
+	tomlVersion = regexp.MustCompile(`^\s*version\s*=\s*"([^"]*)"`)
+)
+
+// SetFileVersion returns content of the file f with its version set to v.
+// A leading "v" of the old version is kept.
+func SetFileVersion(f VersionFile, content []byte, v Version) ([]byte, error) {
+	start, end, err := locateVersion(f, content)
+	if err != nil {
+		return nil, err
+	}
+
+	version := v.String()
+	if strings.HasPrefix(string(content[start:end]), "v") {
+		version = "v" + version
+	}
+
+	replaced := make([]byte, 0, len(content)+len(version))
+	replaced = append(replaced, content[:start]...)
+	replaced = append(replaced, version...)
+	return append(replaced, content[end:]...), nil
+}
+
+// FileVersion returns the version found in content of the file f.
+func FileVersion(f VersionFile, content []byte) (Version, error) {
+	start, end, err := locateVersion(f, content)
+	if err != nil {
+		return Version{}, err
+	}
+
+	v, err := toVersion(strings.TrimPrefix(string(content[start:end]), "v"))
+	if err != nil {
+		return Version{}, errors.New("Invalid version in `" + f.Path + "`. " + err.Error())
+	}
+
+	return *v, nil
+}
+
+// locateVersion returns the start and end of the version in content of f
+func locateVersion(f VersionFile, content []byte) (int, int, error) {
+	if f.Pattern != "" {
+		re, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			return 0, 0, errors.New("Invalid pattern for `" + f.Path + "`. " + err.Error())
+		}
+		if re.NumSubexp() < 1 {
+			return 0, 0, errors.New("Pattern for `" + f.Path + "` has no group to replace")
+		}
+		return findGroup(f.Path, content, re)
+	}
+
+	switch name := path.Base(f.Path); {
+	case name == "VERSION":
+		return findGroup(f.Path, content, plainVersion)
+	case name == "package.json":
+		return findJSONVersion(f.Path, content)
+	case name == "Chart.yaml":
+		return findGroup(f.Path, content, chartVersion)
+	case name == "Cargo.toml":
+		return findTOMLVersion(f.Path, content, "package", "workspace.package")
+	case name == "pyproject.toml":
+		return findTOMLVersion(f.Path, content, "project", "tool.poetry")
+	case strings.HasSuffix(name, ".go"):
+		return findGroup(f.Path, content, goVersion)
+	}
+
+	return 0, 0, errors.New("Don't know where the version is in `" + f.Path + "`. Configure a pattern for it")
+}
+
+// findGroup locates the first group of the first match of re
+func findGroup(name string, content []byte, re *regexp.Regexp) (int, int, error) {
+	loc := re.FindSubmatchIndex(content)
+	if loc == nil || loc[2] < 0 {
+		return 0, 0, errors.New("No version found in `" + name + "`")
+	}
+
+	return loc[2], loc[3], nil
+}
+
+// findTOMLVersion locates the version key of the first of sections found
+func findTOMLVersion(name string, content []byte, sections ...string) (int, int, error) {
+	lines := strings.SplitAfter(string(content), "\n")
+
+	for _, section := range sections {
+		offset := 0
+		current := ""
+		for _, line := range lines {
+			if m := tomlSection.FindStringSubmatch(line); m != nil {
+				current = strings.TrimSpace(m[1])
+			} else if current == section {
+				if loc := tomlVersion.FindStringSubmatchIndex(line); loc != nil {
+					return offset + loc[2], offset + loc[3], nil
+				}
+			}
+			offset += len(line)
+		}
+	}
+
+	return 0, 0, errors.New("No version found in [" + strings.Join(sections, "] or [") + "] of `" + name + "`")
+}
+
+// findJSONVersion locates the value of the top level "version" key,
+// skipping keys of nested objects like "volta": {"version": ...}
+func findJSONVersion(name string, content []byte) (int, int, error) {
+	depth := 0
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+		case '"':
+			// find the end of the string, skipping escapes
+			start := i + 1
+			for i++; i < len(content) && content[i] != '"'; i++ {
+				if content[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(content) {
+				break
+			}
+
+			if depth != 1 || string(content[start:i]) != "version" {
+				continue
+			}
+			if loc := jsonValue.FindSubmatchIndex(content[i+1:]); loc != nil {
+				return i + 1 + loc[2], i + 1 + loc[3], nil
+			}
+		}
+	}
+
+	return 0, 0, errors.New("No version found in `" + name + "`")
+}
@@ -0,0 +1,128 @@
+package ver
+
+import "testing"
+
+func TestFileVersion(t *testing.T) {
+	tests := []struct {
+		path    string
+		content string
+		version string
+		want    string
+	}{
+		{"VERSION", "1.2.3\n", "1.2.3", "2.0.0\n"},
+		{"VERSION", "v1.2.3\n", "1.2.3", "v2.0.0\n"},
+		{
+			"package.json",
+			`{"name": "app", "version": "1.2.3", "private": true}`,
+			"1.2.3",
+			`{"name": "app", "version": "2.0.0", "private": true}`,
+		},
+		// nested version keys come before the top level one
+		{
+			"package.json",
+			"{\n  \"volta\": {\"node\": \"18.0.0\", \"version\": \"9.9.9\"},\n  \"engines\": [{\"version\": \"8.8.8\"}],\n  \"version\": \"1.2.3\"\n}\n",
+			"1.2.3",
+			"{\n  \"volta\": {\"node\": \"18.0.0\", \"version\": \"9.9.9\"},\n  \"engines\": [{\"version\": \"8.8.8\"}],\n  \"version\": \"2.0.0\"\n}\n",
+		},
+		// escaped quotes and "version" as a value
+		{
+			"package.json",
+			`{"description": "set \"version\": \"9.9.9\" {", "name": "version", "keywords": ["version"], "version": "v1.2.3"}`,
+			"1.2.3",
+			`{"description": "set \"version\": \"9.9.9\" {", "name": "version", "keywords": ["version"], "version": "v2.0.0"}`,
+		},
+		{
+			"Cargo.toml",
+			"[dependencies.serde]\nversion = \"1.0.100\"\n\n[package]\nname = \"app\"\nversion = \"0.3.0\"\n",
+			"0.3.0",
+			"[dependencies.serde]\nversion = \"1.0.100\"\n\n[package]\nname = \"app\"\nversion = \"2.0.0\"\n",
+		},
+		// the version is inherited from the workspace
+		{
+			"Cargo.toml",
+			"[package]\nname = \"app\"\nversion.workspace = true\n\n[workspace.package]  # shared\nversion = \"0.4.1\"\n",
+			"0.4.1",
+			"[package]\nname = \"app\"\nversion.workspace = true\n\n[workspace.package]  # shared\nversion = \"2.0.0\"\n",
+		},
+		{
+			"pyproject.toml",
+			"[build-system]\nrequires = [\"poetry-core\"]\n\n[tool.poetry]\nversion = \"1.0\"\n",
+			"1.0.0",
+			"[build-system]\nrequires = [\"poetry-core\"]\n\n[tool.poetry]\nversion = \"2.0.0\"\n",
+		},
+		{
+			"charts/app/Chart.yaml",
+			"apiVersion: v2\nappVersion: \"3.1.0\"\nversion: 0.1.0 # chart\n",
+			"0.1.0",
+			"apiVersion: v2\nappVersion: \"3.1.0\"\nversion: 2.0.0 # chart\n",
+		},
+		{
+			"version.go",
+			"package main\n\n// Version of the app\nconst Version = \"v1.2.3-rc.1\"\n",
+			"1.2.3-rc.1",
+			"package main\n\n// Version of the app\nconst Version = \"v2.0.0\"\n",
+		},
+	}
+
+	next := Version{Major: 2}
+
+	for _, test := range tests {
+		f := VersionFile{Path: test.path}
+
+		v, err := FileVersion(f, []byte(test.content))
+		if err != nil {
+			t.Errorf("FileVersion(%s, %q) failed: %s", test.path, test.content, err)
+			continue
+		}
+		if v.String() != test.version {
+			t.Errorf("FileVersion(%s, %q) = %s, want %s", test.path, test.content, v, test.version)
+		}
+
+		content, err := SetFileVersion(f, []byte(test.content), next)
+		if err != nil {
+			t.Errorf("SetFileVersion(%s, %q) failed: %s", test.path, test.content, err)
+			continue
+		}
+		if string(content) != test.want {
+			t.Errorf("SetFileVersion(%s, %q) = %q, want %q", test.path, test.content, content, test.want)
+		}
+	}
+}
+
+func TestFileVersionPattern(t *testing.T) {
+	f := VersionFile{Path: "build.gradle", Pattern: `version\s*=\s*'([^']+)'`}
+
+	content, err := SetFileVersion(f, []byte("group = 'app'\nversion = '1.2.3'\n"), Version{Major: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "group = 'app'\nversion = '2.0.0'\n" {
+		t.Errorf("SetFileVersion() = %q", content)
+	}
+}
+
+func TestFileVersionMissing(t *testing.T) {
+	tests := []struct {
+		path    string
+		pattern string
+		content string
+	}{
+		{"package.json", "", `{"volta": {"version": "1.2.3"}}`},
+		{"package.json", "", `{"name": "version"}`},
+		{"package.json", "", `{"description": "\"version\": \"1.2.3\""}`},
+		{"Cargo.toml", "", "[package]\nversion.workspace = true\n"},
+		{"Cargo.toml", "", "[dependencies]\nversion = \"1.0\"\n"},
+		{"Chart.yaml", "", "appVersion: 1.2.3\n"},
+		{"VERSION", "", "\n"},
+		{"setup.cfg", "", "version = 1.2.3\n"},
+		{"build.gradle", `version = '[^']+'`, "version = '1.2.3'\n"},
+		{"build.gradle", `version = '([^']+)`, "name = 'app'\n"},
+	}
+
+	for _, test := range tests {
+		f := VersionFile{Path: test.path, Pattern: test.pattern}
+		if v, err := FileVersion(f, []byte(test.content)); err == nil {
+			t.Errorf("FileVersion(%s, %q) = %s, want an error", test.path, test.content, v)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package ver
+
+import (
+	"errors"
+	"strings"
+)
+
+// TagFormat describes how versions are written in tag names,
+// e.g. "v1.2.0" with Prefix "v".
+type TagFormat struct {
+	// Prefix is written before the version.
+	Prefix string
+	// Suffix is written after the version.
+	Suffix string
+	// Alternatives are further prefixes accepted when parsing tags,
+	// e.g. "" to read "1.2.0" next to "v1.2.0".
+	Alternatives []string
+}
+
+// DefaultTagFormat writes versions like "v1.2.0".
+var DefaultTagFormat = TagFormat{Prefix: "v"}
+
+// Format returns v as written in tag names, without component.
+func (f TagFormat) Format(v Version) string {
+	return f.Prefix + v.String() + f.Suffix
+}
+
+// Tag returns the tag name of v within component. A version parsed from
+// a tag keeps the name of that tag, even if it was written differently.
+func (f TagFormat) Tag(component string, v Version) string {
+	if v.Tag != "" {
+		return v.Tag
+	}
+	if component == "" {
+		return f.Format(v)
+	}
+	return component + "/" + f.Format(v)
+}
+
+// Parse parses the tag name tag. Its component namespace is ignored,
+// the rest has to be the prefix or one of the alternatives followed by
+// the version and the suffix. Missing minor and patch numbers are read as 0.
+// The parsed version keeps tag as its Tag.
+func (f TagFormat) Parse(tag string) (Version, error) {
+	_, name := SplitComponent(tag)
+
+	if !strings.HasSuffix(name, f.Suffix) {
+		return Version{}, errors.New("Tag `" + tag + "` doesn't end with `" + f.Suffix + "`")
+	}
+	name = strings.TrimSuffix(name, f.Suffix)
+
+	var err error
+	for _, prefix := range append([]string{f.Prefix}, f.Alternatives...) {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		var v *Version
+		v, err = toVersion(name[len(prefix):])
+		if err != nil {
+			continue
+		}
+
+		v.Tag = tag
+		return *v, nil
+	}
+
+	if err != nil {
+		return Version{}, errors.New("Invalid version in tag `" + tag + "`. " + err.Error())
+	}
+	return Version{}, errors.New("Tag `" + tag + "` doesn't start with `" + f.Prefix + "`")
+}
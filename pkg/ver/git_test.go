@@ -0,0 +1,70 @@
+package ver
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	git "gopkg.in/libgit2/git2go.v25"
+)
+
+func checkFatal(t *testing.T, err error) {
+	if err != nil {
+		t.Helper()
+		t.Fatal(err)
+	}
+}
+
+var testSignature = &git.Signature{
+	Name:  "Jane Doe",
+	Email: "jane@example.com",
+	When:  time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+}
+
+// createTestRepo creates a repository in a temporary directory
+func createTestRepo(t *testing.T, bare bool) *git.Repository {
+	dir, err := ioutil.TempDir("", "ver")
+	checkFatal(t, err)
+
+	repo, err := git.InitRepository(dir, bare)
+	checkFatal(t, err)
+
+	return repo
+}
+
+func cleanupTestRepo(t *testing.T, repo *git.Repository) {
+	path := repo.Workdir()
+	if repo.IsBare() {
+		path = repo.Path()
+	}
+	repo.Free()
+
+	checkFatal(t, os.RemoveAll(path))
+}
+
+// commitTestRepo commits the empty tree on top of HEAD
+func commitTestRepo(t *testing.T, repo *git.Repository, message string) *git.Commit {
+	index, err := repo.Index()
+	checkFatal(t, err)
+	defer index.Free()
+
+	treeId, err := index.WriteTree()
+	checkFatal(t, err)
+	tree, err := repo.LookupTree(treeId)
+	checkFatal(t, err)
+	defer tree.Free()
+
+	parents := []*git.Commit{}
+	if head, err := GetHeadCommit(repo); err == nil {
+		parents = append(parents, head)
+	}
+
+	id, err := repo.CreateCommit("HEAD", testSignature, testSignature, message, tree, parents...)
+	checkFatal(t, err)
+
+	commit, err := repo.LookupCommit(id)
+	checkFatal(t, err)
+
+	return commit
+}
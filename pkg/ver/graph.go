@@ -0,0 +1,140 @@
+package ver
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Dependents returns the components which directly depend on name.
+func Dependents(components []Component, name string) []string {
+	dependents := []string{}
+	for _, c := range components {
+		for _, dep := range c.Depends {
+			if NormalizeComponent(dep) == name {
+				dependents = append(dependents, NormalizeComponent(c.Name))
+				break
+			}
+		}
+	}
+
+	sort.Strings(dependents)
+	return dependents
+}
+
+// ReleaseOrder returns roots in the order they have to be released,
+// dependencies first. With cascade every component transitively depending on
+// one of roots is added as well. Components with no order between them are
+// sorted by name. Dependency cycles are an error.
+func ReleaseOrder(components []Component, roots []string, cascade bool) ([]string, error) {
+	selected := map[string]bool{}
+	queue := []string{}
+	for _, root := range roots {
+		root = NormalizeComponent(root)
+		if !selected[root] {
+			selected[root] = true
+			queue = append(queue, root)
+		}
+	}
+
+	for cascade && len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		for _, dependent := range Dependents(components, name) {
+			if !selected[dependent] {
+				selected[dependent] = true
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	// dependencies between the selected components
+	depends := map[string][]string{}
+	for _, c := range components {
+		name := NormalizeComponent(c.Name)
+		if !selected[name] {
+			continue
+		}
+		for _, dep := range c.Depends {
+			dep = NormalizeComponent(dep)
+			if selected[dep] {
+				depends[name] = append(depends[name], dep)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(selected))
+	for name := range selected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	order := []string{}
+	done := map[string]bool{}
+	for len(order) < len(names) {
+		progress := false
+		for _, name := range names {
+			if done[name] || !allDone(depends[name], done) {
+				continue
+			}
+			done[name] = true
+			order = append(order, name)
+			progress = true
+		}
+
+		if !progress {
+			return nil, errors.New("Dependency cycle between " + strings.Join(cycleOf(names, depends, done), ", "))
+		}
+	}
+
+	return order, nil
+}
+
+// cycleOf returns the components which aren't done because of a cycle,
+// leaving out the ones which only wait for it
+func cycleOf(names []string, depends map[string][]string, done map[string]bool) []string {
+	left := map[string]bool{}
+	for _, name := range names {
+		if !done[name] {
+			left[name] = true
+		}
+	}
+
+	// drop components nothing left depends on until only cycles remain
+	for changed := true; changed; {
+		changed = false
+		for _, name := range names {
+			if left[name] && !dependedOn(name, depends, left) {
+				delete(left, name)
+				changed = true
+			}
+		}
+	}
+
+	cycle := []string{}
+	for _, name := range names {
+		if left[name] {
+			cycle = append(cycle, name)
+		}
+	}
+	return cycle
+}
+
+func dependedOn(name string, depends map[string][]string, left map[string]bool) bool {
+	for other := range left {
+		if containsString(depends[other], name) {
+			return true
+		}
+	}
+	return false
+}
+
+func allDone(names []string, done map[string]bool) bool {
+	for _, name := range names {
+		if !done[name] {
+			return false
+		}
+	}
+	return true
+}
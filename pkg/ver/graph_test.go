@@ -0,0 +1,81 @@
+package ver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReleaseOrder(t *testing.T) {
+	components := []Component{
+		{Name: "libs/core"},
+		{Name: "libs/util", Depends: []string{"libs/core"}},
+		{Name: "services/api", Depends: []string{"libs/core", "libs/util"}},
+		{Name: "services/web", Depends: []string{"/services/api/"}},
+		{Name: "tools/cli", Depends: []string{"libs/util"}},
+		{Name: "docs"},
+	}
+
+	tests := []struct {
+		roots   []string
+		cascade bool
+		want    string
+	}{
+		{[]string{"libs/core"}, false, "libs/core"},
+		{[]string{"services/api", "libs/core"}, false, "libs/core services/api"},
+		{[]string{"services/web", "services/api", "libs/util"}, false, "libs/util services/api services/web"},
+		{[]string{"/libs/util/", "libs/util"}, false, "libs/util"},
+		{[]string{"docs", "tools/cli"}, false, "docs tools/cli"},
+		{[]string{"libs/core"}, true, "libs/core libs/util services/api services/web tools/cli"},
+		{[]string{"libs/util"}, true, "libs/util services/api services/web tools/cli"},
+		{[]string{"services/api"}, true, "services/api services/web"},
+		{[]string{"services/web"}, true, "services/web"},
+		{[]string{"other"}, true, "other"},
+	}
+
+	for _, test := range tests {
+		order, err := ReleaseOrder(components, test.roots, test.cascade)
+		if err != nil {
+			t.Errorf("ReleaseOrder(%v, %t) failed: %s", test.roots, test.cascade, err)
+			continue
+		}
+		if got := strings.Join(order, " "); got != test.want {
+			t.Errorf("ReleaseOrder(%v, %t) = %s, want %s", test.roots, test.cascade, got, test.want)
+		}
+	}
+}
+
+func TestReleaseOrderCycle(t *testing.T) {
+	components := []Component{
+		{Name: "a", Depends: []string{"c"}},
+		{Name: "b", Depends: []string{"a"}},
+		{Name: "c", Depends: []string{"b"}},
+		{Name: "d", Depends: []string{"a"}},
+	}
+
+	if _, err := ReleaseOrder(components, []string{"a"}, true); err == nil {
+		t.Error("cycle a -> b -> c -> a not detected")
+	} else if !strings.HasSuffix(err.Error(), " a, b, c") {
+		t.Errorf("cycle error names the wrong components: %s", err)
+	}
+
+	// the cycle isn't part of the release without cascade
+	order, err := ReleaseOrder(components, []string{"a", "d"}, false)
+	if err != nil || strings.Join(order, " ") != "a d" {
+		t.Errorf("ReleaseOrder(a, d) = %v, %v", order, err)
+	}
+}
+
+func TestDependents(t *testing.T) {
+	components := []Component{
+		{Name: "web", Depends: []string{"api", "/core/"}},
+		{Name: "api", Depends: []string{"core"}},
+		{Name: "cli"},
+	}
+
+	if got := strings.Join(Dependents(components, "core"), " "); got != "api web" {
+		t.Errorf("Dependents(core) = %s, want api web", got)
+	}
+	if got := Dependents(components, "cli"); len(got) != 0 {
+		t.Errorf("Dependents(cli) = %v, want none", got)
+	}
+}
@@ -0,0 +1,74 @@
+package ver
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultTagMessage is the tag message template used if none is configured.
+const DefaultTagMessage = "{{.Tag}}"
+
+// TagMessageData is passed to tag message templates.
+type TagMessageData struct {
+	Component string
+	// Previous is the latest version before this one.
+	// Its Tag is empty if there is none.
+	Previous    Version
+	PreviousTag string
+	Version     Version
+	Tag         string
+	// Commits since the previous version, newest first.
+	Commits []ChangelogEntry
+	Author  string
+	Email   string
+	Date    time.Time
+	// Changelog holds Commits, its Markdown method renders them grouped
+	// by Conventional Commit type.
+	Changelog ChangelogSection
+}
+
+var tagMessageFuncs = template.FuncMap{
+	// first line of a commit message
+	"summary": func(message string) string {
+		return strings.SplitN(strings.TrimSpace(message), "\n", 2)[0]
+	},
+	// abbreviated commit id
+	"short": func(id string) string {
+		if len(id) > 7 {
+			return id[:7]
+		}
+		return id
+	},
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+}
+
+// RenderTagMessage renders the text/template text with data.
+// Besides the fields of TagMessageData the functions summary, short and date
+// are available, e.g.
+//
+//	Release {{.Version}}
+//	{{range .Commits}}
+//	- {{short .Id}} {{summary .Message}}{{end}}
+func RenderTagMessage(text string, data TagMessageData) (string, error) {
+	tmpl, err := template.New("message").Funcs(tagMessageFuncs).Parse(text)
+	if err != nil {
+		return "", errors.New("Invalid tag message template. " + err.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", errors.New("Unable to render tag message. " + err.Error())
+	}
+
+	message := strings.TrimSpace(buf.String())
+	if message == "" {
+		return "", errors.New("Tag message is empty")
+	}
+
+	return message + "\n", nil
+}
@@ -0,0 +1,296 @@
+package ver
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	git "gopkg.in/libgit2/git2go.v25"
+)
+
+// PushOptions configures how tags are pushed.
+type PushOptions struct {
+	// Remote is the name of the remote to push to. Defaults to "origin".
+	Remote string
+	// SSHKey is the path of a private key to try before the ssh agent.
+	// The public key is expected next to it with a ".pub" suffix.
+	SSHKey string
+	// Branch is pushed along with the tags if set,
+	// e.g. when the tagged commit was just created.
+	Branch string
+}
+
+// PushTag pushes the single tag name to the configured remote.
+// Other local tags are left alone.
+func PushTag(repo *git.Repository, name string, opts PushOptions) error {
+	return PushTags(repo, []string{name}, opts)
+}
+
+// PushTags pushes the tags names to the configured remote in a single push.
+// Other local tags are left alone.
+// If the push fails the error is a *PushError telling which refs the remote
+// accepted anyway, since a push isn't atomic.
+func PushTags(repo *git.Repository, names []string, opts PushOptions) error {
+	refspecs := make([]string, 0, len(names))
+	for _, name := range names {
+		ref := "refs/tags/" + name
+		refspecs = append(refspecs, ref+":"+ref)
+	}
+
+	if opts.Branch != "" {
+		ref := "refs/heads/" + opts.Branch
+		refspecs = append(refspecs, ref+":"+ref)
+	}
+
+	return push(repo, refspecs, opts, "Unable to push tags `"+strings.Join(names, "`, `")+"`")
+}
+
+// PushRelease pushes the tags names of a release like PushTags. If the push
+// fails, the tags the remote accepted anyway are deleted from it again and
+// all of names are removed locally, so a release is pushed as a whole or not
+// at all. The error tells whether the rollback succeeded.
+func PushRelease(repo *git.Repository, names []string, opts PushOptions) error {
+	err := PushTags(repo, names, opts)
+	if err == nil {
+		return nil
+	}
+
+	failures := []string{}
+	if pushErr, ok := err.(*PushError); ok {
+		accepted := []string{}
+		for _, ref := range pushErr.Accepted {
+			if strings.HasPrefix(ref, "refs/tags/") {
+				accepted = append(accepted, strings.TrimPrefix(ref, "refs/tags/"))
+			}
+		}
+		if len(accepted) > 0 {
+			if err := DeleteRemoteTags(repo, accepted, opts); err != nil {
+				failures = append(failures, "Tags `"+strings.Join(accepted, "`, `")+"` are left on the remote. "+err.Error())
+			}
+		}
+	}
+
+	for _, name := range names {
+		if err := repo.Tags.Remove(name); err != nil {
+			failures = append(failures, "Unable to remove tag `"+name+"`. "+err.Error())
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.New(err.Error() + ". " + strings.Join(failures, ". "))
+	}
+	return errors.New(err.Error() + ". Tags `" + strings.Join(names, "`, `") + "` removed again")
+}
+
+// DeleteRemoteTags deletes the tags names on the configured remote,
+// e.g. to undo a push which was only accepted in part.
+func DeleteRemoteTags(repo *git.Repository, names []string, opts PushOptions) error {
+	refspecs := make([]string, 0, len(names))
+	for _, name := range names {
+		refspecs = append(refspecs, ":refs/tags/"+name)
+	}
+
+	return push(repo, refspecs, opts, "Unable to delete tags `"+strings.Join(names, "`, `")+"`")
+}
+
+// PushError is returned if a push failed in part or as a whole.
+type PushError struct {
+	Remote string
+	// Accepted are the refs the remote updated nonetheless.
+	Accepted []string
+	// Rejected are the refs the remote refused, with its reason.
+	Rejected []string
+
+	message string
+}
+
+func (e *PushError) Error() string {
+	return e.message
+}
+
+func push(repo *git.Repository, refspecs []string, opts PushOptions, failure string) error {
+	if opts.Remote == "" {
+		opts.Remote = "origin"
+	}
+
+	remote, err := repo.Remotes.Lookup(opts.Remote)
+	if err != nil {
+		return errors.New("Couldn't find remote `" + opts.Remote + "`. " + err.Error())
+	}
+	defer remote.Free()
+
+	pushErr := &PushError{Remote: opts.Remote}
+
+	pushOpts := &git.PushOptions{
+		RemoteCallbacks: git.RemoteCallbacks{
+			CredentialsCallback: credentialsCallback(repo, opts),
+			PushUpdateReferenceCallback: func(refname, status string) git.ErrorCode {
+				// an empty status means the remote accepted the update
+				if status != "" {
+					pushErr.Rejected = append(pushErr.Rejected, refname+" ("+status+")")
+				} else {
+					pushErr.Accepted = append(pushErr.Accepted, refname)
+				}
+				return git.ErrOk
+			},
+		},
+	}
+
+	err = remote.Push(refspecs, pushOpts)
+	if err != nil {
+		pushErr.message = failure + " to `" + opts.Remote + "`. " + err.Error()
+		return pushErr
+	}
+
+	if len(pushErr.Rejected) > 0 {
+		pushErr.message = "Remote `" + opts.Remote + "` rejected " + strings.Join(pushErr.Rejected, ", ")
+		return pushErr
+	}
+
+	return nil
+}
+
+// credentialsCallback tries the configured ssh key and the ssh agent for
+// ssh remotes and the configured git credential helper for http remotes.
+// libgit2 calls it again after every failed attempt,
+// so each method is only tried once.
+func credentialsCallback(repo *git.Repository, opts PushOptions) git.CredentialsCallback {
+	attempt := 0
+
+	return func(remoteURL string, username string, allowed git.CredType) (git.ErrorCode, *git.Cred) {
+		attempt++
+
+		if allowed&git.CredTypeSshKey != 0 {
+			if username == "" {
+				username = "git"
+			}
+
+			methods := []func() (int, git.Cred){}
+			if opts.SSHKey != "" {
+				key := expandHome(opts.SSHKey)
+				methods = append(methods, func() (int, git.Cred) {
+					return git.NewCredSshKey(username, key+".pub", key, "")
+				})
+			}
+			methods = append(methods, func() (int, git.Cred) {
+				return git.NewCredSshKeyFromAgent(username)
+			})
+
+			if attempt > len(methods) {
+				return git.ErrAuth, nil
+			}
+
+			ret, cred := methods[attempt-1]()
+			if ret != 0 {
+				return git.ErrorCode(ret), nil
+			}
+			return git.ErrOk, &cred
+		}
+
+		if allowed&git.CredTypeUserpassPlaintext != 0 {
+			if attempt > 1 {
+				return git.ErrAuth, nil
+			}
+
+			user, password, err := credentialFill(repo, remoteURL, username)
+			if err != nil {
+				return git.ErrAuth, nil
+			}
+
+			ret, cred := git.NewCredUserpassPlaintext(user, password)
+			if ret != 0 {
+				return git.ErrorCode(ret), nil
+			}
+			return git.ErrOk, &cred
+		}
+
+		return git.ErrPassthrough, nil
+	}
+}
+
+// credentialFill asks the git credential helper configured in
+// credential.helper for the username and password of remoteURL.
+// See gitcredentials(7) for the helper protocol.
+func credentialFill(repo *git.Repository, remoteURL string, username string) (string, string, error) {
+	conf, err := repo.Config()
+	if err != nil {
+		return "", "", err
+	}
+	defer conf.Free()
+
+	helper, err := conf.LookupString("credential.helper")
+	if err != nil || helper == "" {
+		return "", "", errors.New("No credential.helper configured")
+	}
+
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	var script string
+	switch {
+	case strings.HasPrefix(helper, "!"):
+		script = helper[1:] + " get"
+	case filepath.IsAbs(helper):
+		script = helper + " get"
+	default:
+		script = "git-credential-" + helper + " get"
+	}
+
+	var input bytes.Buffer
+	input.WriteString("protocol=" + u.Scheme + "\n")
+	input.WriteString("host=" + u.Host + "\n")
+	input.WriteString("path=" + strings.TrimPrefix(u.Path, "/") + "\n")
+	if username != "" {
+		input.WriteString("username=" + username + "\n")
+	}
+	input.WriteString("\n")
+
+	helperCmd := exec.Command("sh", "-c", script)
+	helperCmd.Stdin = &input
+	helperCmd.Stderr = os.Stderr
+	out, err := helperCmd.Output()
+	if err != nil {
+		return "", "", errors.New("Credential helper `" + helper + "` failed. " + err.Error())
+	}
+
+	password := ""
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		kv := strings.SplitN(scanner.Text(), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "username":
+			username = kv[1]
+		case "password":
+			password = kv[1]
+		}
+	}
+
+	if username == "" || password == "" {
+		return "", "", errors.New("Credential helper `" + helper + "` returned no credentials")
+	}
+
+	return username, password, nil
+}
+
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, path[2:])
+}
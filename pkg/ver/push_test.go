@@ -0,0 +1,271 @@
+package ver
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	git "gopkg.in/libgit2/git2go.v25"
+)
+
+// createTestRemote creates a bare repository as remote "origin" of repo
+func createTestRemote(t *testing.T, repo *git.Repository) *git.Repository {
+	remote := createTestRepo(t, true)
+
+	r, err := repo.Remotes.Create("origin", remote.Path())
+	checkFatal(t, err)
+	r.Free()
+
+	return remote
+}
+
+// remoteTarget returns the target of ref in remote, nil if it doesn't exist
+func remoteTarget(t *testing.T, remote *git.Repository, ref string) *git.Oid {
+	r, err := remote.References.Lookup(ref)
+	if err != nil {
+		return nil
+	}
+	defer r.Free()
+
+	return r.Target()
+}
+
+func TestPushTags(t *testing.T) {
+	repo := createTestRepo(t, false)
+	defer cleanupTestRepo(t, repo)
+	remote := createTestRemote(t, repo)
+	defer cleanupTestRepo(t, remote)
+
+	commit := commitTestRepo(t, repo, "initial")
+
+	id, err := CreateTag(repo, "v1.0.0", commit, testSignature, "v1.0.0\n", nil)
+	checkFatal(t, err)
+	_, err = repo.Tags.CreateLightweight("v1.1.0", commit, false)
+	checkFatal(t, err)
+
+	checkFatal(t, PushTag(repo, "v1.0.0", PushOptions{}))
+
+	if target := remoteTarget(t, remote, "refs/tags/v1.0.0"); target == nil || !target.Equal(id) {
+		t.Errorf("remote has v1.0.0 at %v, want %s", target, id)
+	}
+	if target := remoteTarget(t, remote, "refs/tags/v1.1.0"); target != nil {
+		t.Error("v1.1.0 was pushed as well")
+	}
+
+	branch, err := GetCurrentBranch(repo)
+	checkFatal(t, err)
+
+	checkFatal(t, PushTags(repo, []string{"v1.1.0"}, PushOptions{Remote: "origin", Branch: branch}))
+
+	if target := remoteTarget(t, remote, "refs/tags/v1.1.0"); target == nil || !target.Equal(commit.Id()) {
+		t.Errorf("remote has v1.1.0 at %v, want %s", target, commit.Id())
+	}
+	if target := remoteTarget(t, remote, "refs/heads/"+branch); target == nil || !target.Equal(commit.Id()) {
+		t.Errorf("remote has %s at %v, want %s", branch, target, commit.Id())
+	}
+
+	checkFatal(t, DeleteRemoteTags(repo, []string{"v1.0.0", "v1.1.0"}, PushOptions{}))
+
+	for _, ref := range []string{"refs/tags/v1.0.0", "refs/tags/v1.1.0"} {
+		if target := remoteTarget(t, remote, ref); target != nil {
+			t.Errorf("%s wasn't deleted", ref)
+		}
+	}
+}
+
+func TestPushTagsRejected(t *testing.T) {
+	repo := createTestRepo(t, false)
+	defer cleanupTestRepo(t, repo)
+	remote := createTestRemote(t, repo)
+	defer cleanupTestRepo(t, remote)
+
+	commit := commitTestRepo(t, repo, "initial")
+	_, err := repo.Tags.CreateLightweight("v1.0.0", commit, false)
+	checkFatal(t, err)
+	checkFatal(t, PushTag(repo, "v1.0.0", PushOptions{}))
+
+	// v1.0.0 moved to an unrelated commit
+	tree, err := commit.Tree()
+	checkFatal(t, err)
+	defer tree.Free()
+	otherId, err := repo.CreateCommit("", testSignature, testSignature, "other", tree)
+	checkFatal(t, err)
+	other, err := repo.LookupCommit(otherId)
+	checkFatal(t, err)
+
+	checkFatal(t, repo.Tags.Remove("v1.0.0"))
+	_, err = repo.Tags.CreateLightweight("v1.0.0", other, false)
+	checkFatal(t, err)
+	_, err = repo.Tags.CreateLightweight("v1.0.1", commit, false)
+	checkFatal(t, err)
+
+	err = PushTags(repo, []string{"v1.0.0", "v1.0.1"}, PushOptions{})
+	if err == nil {
+		t.Fatal("moved tag was pushed")
+	}
+
+	pushErr, ok := err.(*PushError)
+	if !ok {
+		t.Fatalf("error is %T, want *PushError: %s", err, err)
+	}
+	if pushErr.Remote != "origin" {
+		t.Errorf("error names remote %q", pushErr.Remote)
+	}
+	for _, ref := range pushErr.Accepted {
+		if remoteTarget(t, remote, ref) == nil {
+			t.Errorf("accepted ref %s doesn't exist on the remote", ref)
+		}
+	}
+
+	if target := remoteTarget(t, remote, "refs/tags/v1.0.0"); target == nil || !target.Equal(commit.Id()) {
+		t.Errorf("remote has v1.0.0 at %v, want %s", target, commit.Id())
+	}
+}
+
+func TestPushTagsUnknownRemote(t *testing.T) {
+	repo := createTestRepo(t, false)
+	defer cleanupTestRepo(t, repo)
+
+	commit := commitTestRepo(t, repo, "initial")
+	_, err := repo.Tags.CreateLightweight("v1.0.0", commit, false)
+	checkFatal(t, err)
+
+	if err := PushTag(repo, "v1.0.0", PushOptions{Remote: "upstream"}); err == nil {
+		t.Error("pushed to a remote which doesn't exist")
+	}
+}
+
+func TestCredentialHelper(t *testing.T) {
+	repo := createTestRepo(t, false)
+	defer cleanupTestRepo(t, repo)
+
+	input := filepath.Join(repo.Workdir(), "input")
+
+	conf, err := repo.Config()
+	checkFatal(t, err)
+	err = conf.SetString("credential.helper", "!f() { cat > '"+input+"'; echo username=jane; echo password=secret; }; f")
+	conf.Free()
+	checkFatal(t, err)
+
+	callback := credentialsCallback(repo, PushOptions{})
+
+	code, cred := callback("https://example.com/org/repo.git", "", git.CredTypeUserpassPlaintext)
+	if code != git.ErrOk || cred == nil {
+		t.Fatalf("first attempt returned %d, %v", code, cred)
+	}
+
+	sent, err := ioutil.ReadFile(input)
+	checkFatal(t, err)
+	for _, line := range []string{"protocol=https\n", "host=example.com\n", "path=org/repo.git\n"} {
+		if !strings.Contains(string(sent), line) {
+			t.Errorf("helper didn't get %q, got %q", line, sent)
+		}
+	}
+
+	// the helper isn't asked again after its credentials failed
+	if code, _ := callback("https://example.com/org/repo.git", "", git.CredTypeUserpassPlaintext); code != git.ErrAuth {
+		t.Errorf("second attempt returned %d, want %d", code, git.ErrAuth)
+	}
+
+	user, password, err := credentialFill(repo, "https://example.com/org/repo.git", "")
+	if err != nil || user != "jane" || password != "secret" {
+		t.Errorf("credentialFill() = %q, %q, %v", user, password, err)
+	}
+}
+
+func TestCredentialsCallbackSSH(t *testing.T) {
+	repo := createTestRepo(t, false)
+	defer cleanupTestRepo(t, repo)
+
+	key := filepath.Join(os.TempDir(), "ver-missing-key")
+	callback := credentialsCallback(repo, PushOptions{SSHKey: key})
+
+	// the key, then the agent, then giving up
+	callback("ssh://git@example.com/repo.git", "git", git.CredTypeSshKey)
+	callback("ssh://git@example.com/repo.git", "git", git.CredTypeSshKey)
+	if code, _ := callback("ssh://git@example.com/repo.git", "git", git.CredTypeSshKey); code != git.ErrAuth {
+		t.Errorf("third attempt returned %d, want %d", code, git.ErrAuth)
+	}
+
+	callback = credentialsCallback(repo, PushOptions{})
+	if code, _ := callback("https://example.com/repo.git", "", git.CredTypeDefault); code != git.ErrPassthrough {
+		t.Errorf("unsupported credential type returned %d, want %d", code, git.ErrPassthrough)
+	}
+}
+
+func TestPushRelease(t *testing.T) {
+	repo := createTestRepo(t, false)
+	defer cleanupTestRepo(t, repo)
+	remote := createTestRemote(t, repo)
+	defer cleanupTestRepo(t, remote)
+
+	commit := commitTestRepo(t, repo, "initial")
+	names := []string{"libs/core/v0.2.0", "services/api/v1.0.0"}
+	for _, name := range names {
+		_, err := repo.Tags.CreateLightweight(name, commit, false)
+		checkFatal(t, err)
+	}
+
+	checkFatal(t, PushRelease(repo, names, PushOptions{}))
+
+	for _, name := range names {
+		if remoteTarget(t, remote, "refs/tags/"+name) == nil {
+			t.Errorf("%s wasn't pushed", name)
+		}
+		if _, err := GetTagCommit(repo, name); err != nil {
+			t.Errorf("%s was removed locally", name)
+		}
+	}
+}
+
+func TestPushReleaseRollback(t *testing.T) {
+	repo := createTestRepo(t, false)
+	defer cleanupTestRepo(t, repo)
+	remote := createTestRemote(t, repo)
+	defer cleanupTestRepo(t, remote)
+
+	// services/api/v1.0.0 was released from another commit meanwhile
+	commit := commitTestRepo(t, repo, "initial")
+	_, err := repo.Tags.CreateLightweight("services/api/v1.0.0", commit, false)
+	checkFatal(t, err)
+	checkFatal(t, PushTag(repo, "services/api/v1.0.0", PushOptions{}))
+	checkFatal(t, repo.Tags.Remove("services/api/v1.0.0"))
+
+	tree, err := commit.Tree()
+	checkFatal(t, err)
+	defer tree.Free()
+	otherId, err := repo.CreateCommit("", testSignature, testSignature, "other", tree)
+	checkFatal(t, err)
+	other, err := repo.LookupCommit(otherId)
+	checkFatal(t, err)
+
+	names := []string{"libs/core/v0.2.0", "services/api/v1.0.0", "services/web/v2.1.0"}
+	for _, name := range names {
+		_, err := repo.Tags.CreateLightweight(name, other, false)
+		checkFatal(t, err)
+	}
+
+	err = PushRelease(repo, names, PushOptions{})
+	if err == nil {
+		t.Fatal("release with a conflicting tag was pushed")
+	}
+	if !strings.HasSuffix(err.Error(), "removed again") {
+		t.Errorf("rollback failed: %s", err)
+	}
+
+	for _, name := range names {
+		if _, err := GetTagCommit(repo, name); err == nil {
+			t.Errorf("%s is left locally", name)
+		}
+	}
+	for _, name := range []string{"libs/core/v0.2.0", "services/web/v2.1.0"} {
+		if remoteTarget(t, remote, "refs/tags/"+name) != nil {
+			t.Errorf("%s is left on the remote", name)
+		}
+	}
+	if target := remoteTarget(t, remote, "refs/tags/services/api/v1.0.0"); target == nil || !target.Equal(commit.Id()) {
+		t.Errorf("remote has services/api/v1.0.0 at %v, want %s", target, commit.Id())
+	}
+}
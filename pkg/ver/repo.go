@@ -0,0 +1,178 @@
+package ver
+
+import (
+	"errors"
+	"sync"
+
+	git "gopkg.in/libgit2/git2go.v25"
+)
+
+// Options configure a Repo.
+type Options struct {
+	// Format of the tag names. Unset means DefaultTagFormat, use
+	// TagFormat{Alternatives: []string{""}} for tags without prefix.
+	Format TagFormat
+	// Component is the tag namespace of the versions.
+	// It's empty for the repository root.
+	Component string
+	// Push configures where Push pushes to.
+	Push PushOptions
+}
+
+// TagOptions configure CreateTag.
+type TagOptions struct {
+	// Target is the commit to tag. Defaults to HEAD.
+	Target *git.Commit
+	// Tagger defaults to the identity of GetGitUser.
+	Tagger *git.Signature
+	// Message defaults to the tag name.
+	Message string
+	// Lightweight creates a plain ref instead of a tag object.
+	Lightweight bool
+	// Signer signs the tag if set.
+	Signer Signer
+}
+
+// Repo gives access to the versions of a git repository.
+// It's safe for concurrent use. Calls on the same Repo are serialized since
+// a libgit2 repository must not be used by several goroutines at once,
+// separate Repos work in parallel.
+type Repo struct {
+	mu    sync.Mutex
+	repo  *git.Repository
+	owned bool
+	opts  Options
+}
+
+// Open opens the git repository at path.
+// The Repo must be closed to release the repository.
+func Open(path string, opts Options) (*Repo, error) {
+	repo, err := git.OpenRepository(path)
+	if err != nil {
+		return nil, errors.New("Directory doesn't appear to be a git repository. " + err.Error())
+	}
+
+	r := NewRepo(repo, opts)
+	r.owned = true
+	return r, nil
+}
+
+// NewRepo wraps an open git repository.
+// The caller must not use repo concurrently with the Repo and stays
+// responsible for freeing it.
+func NewRepo(repo *git.Repository, opts Options) *Repo {
+	if isZeroFormat(opts.Format) {
+		opts.Format = DefaultTagFormat
+	}
+	return &Repo{repo: repo, opts: opts}
+}
+
+func isZeroFormat(f TagFormat) bool {
+	return f.Prefix == "" && f.Suffix == "" && f.Alternatives == nil
+}
+
+// Close releases the repository if r opened it. r can't be used afterwards.
+func (r *Repo) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.repo != nil && r.owned {
+		r.repo.Free()
+	}
+	r.repo = nil
+	return nil
+}
+
+// Options returns the options of r.
+func (r *Repo) Options() Options {
+	return r.opts
+}
+
+// TagName returns the name of the tag of v.
+func (r *Repo) TagName(v Version) string {
+	return r.opts.Format.Tag(r.opts.Component, v)
+}
+
+// Versions returns the versions of all tags in the component of r.
+func (r *Repo) Versions() (Versions, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return GetVersions(r.repo, r.opts.Component, r.opts.Format)
+}
+
+// Latest returns the version with the highest precedence.
+// It's 0.0.0 if there is none.
+func (r *Repo) Latest() (Version, error) {
+	versions, err := r.Versions()
+	if err != nil {
+		return Version{}, err
+	}
+
+	return versions.Latest(), nil
+}
+
+// Next returns the latest version incremented by kind.
+func (r *Repo) Next(kind BumpKind) (Version, error) {
+	latest, err := r.Latest()
+	if err != nil {
+		return Version{}, err
+	}
+
+	return latest.Bump(kind), nil
+}
+
+// CreateTag creates the tag of v and returns its name.
+func (r *Repo) CreateTag(v Version, opts TagOptions) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := r.TagName(v)
+
+	target := opts.Target
+	if target == nil {
+		head, err := GetHeadCommit(r.repo)
+		if err != nil {
+			return "", errors.New("Unable to resolve HEAD. " + err.Error())
+		}
+		target = head
+	}
+
+	if opts.Lightweight {
+		if opts.Signer != nil {
+			return "", errors.New("Lightweight tags can't be signed")
+		}
+		if _, err := r.repo.Tags.CreateLightweight(name, target, false); err != nil {
+			return "", errors.New("Unable to create tag `" + name + "`. " + err.Error())
+		}
+		return name, nil
+	}
+
+	tagger := opts.Tagger
+	if tagger == nil {
+		user, err := GetGitUser(r.repo, "", "")
+		if err != nil {
+			return "", err
+		}
+		tagger = user
+	}
+
+	message := opts.Message
+	if message == "" {
+		message = name
+	}
+
+	if _, err := CreateTag(r.repo, name, target, tagger, message, opts.Signer); err != nil {
+		return "", errors.New("Unable to create tag `" + name + "`. " + err.Error())
+	}
+
+	return name, nil
+}
+
+// Push pushes the tags names to the remote of the options.
+func (r *Repo) Push(names ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return PushTags(r.repo, names, r.opts.Push)
+}
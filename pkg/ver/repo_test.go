@@ -0,0 +1,45 @@
+package ver
+
+import "testing"
+
+func TestRepoDefaultFormat(t *testing.T) {
+	repo := createTestRepo(t, false)
+	defer cleanupTestRepo(t, repo)
+
+	v := Version{Major: 1, Minor: 2}
+
+	r := NewRepo(repo, Options{})
+	if name := r.TagName(v); name != "v1.2.0" {
+		t.Errorf("TagName() = %q with unset format, want v1.2.0", name)
+	}
+
+	r = NewRepo(repo, Options{Format: TagFormat{Alternatives: []string{""}}, Component: "api"})
+	if name := r.TagName(v); name != "api/1.2.0" {
+		t.Errorf("TagName() = %q without prefix, want api/1.2.0", name)
+	}
+}
+
+func TestRepoClose(t *testing.T) {
+	repo := createTestRepo(t, false)
+	defer cleanupTestRepo(t, repo)
+	commitTestRepo(t, repo, "initial")
+
+	r, err := Open(repo.Workdir(), Options{})
+	checkFatal(t, err)
+
+	name, err := r.CreateTag(Version{Major: 1}, TagOptions{Tagger: testSignature})
+	checkFatal(t, err)
+	if name != "v1.0.0" {
+		t.Errorf("created %q, want v1.0.0", name)
+	}
+
+	checkFatal(t, r.Close())
+	checkFatal(t, r.Close())
+
+	// a wrapped repository stays open
+	wrapped := NewRepo(repo, Options{})
+	checkFatal(t, wrapped.Close())
+	if _, err := GetVersions(repo, "", DefaultTagFormat); err != nil {
+		t.Errorf("repository unusable after closing its Repo: %s", err)
+	}
+}
@@ -0,0 +1,253 @@
+package ver
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Signature formats as in the git config key gpg.format.
+const (
+	SignFormatOpenPGP = "openpgp"
+	SignFormatSSH     = "ssh"
+)
+
+const (
+	pgpSignatureHeader = "-----BEGIN PGP SIGNATURE-----"
+	sshSignatureHeader = "-----BEGIN SSH SIGNATURE-----"
+)
+
+// Signer signs the payload of a tag object.
+// The returned signature is appended to the payload as is.
+type Signer interface {
+	Sign(payload []byte) ([]byte, error)
+}
+
+// Verifier checks a signature of a payload and returns who signed it.
+type Verifier interface {
+	Verify(payload []byte, signature []byte) (string, error)
+}
+
+// TagObject is an annotated tag as stored in the object database.
+type TagObject struct {
+	Object      string
+	Type        string
+	Tag         string
+	TaggerName  string
+	TaggerEmail string
+	When        time.Time
+	Message     string
+}
+
+// Bytes returns the raw tag object, see git-mktag(1).
+func (t TagObject) Bytes() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "object %s\n", t.Object)
+	fmt.Fprintf(&buf, "type %s\n", t.Type)
+	fmt.Fprintf(&buf, "tag %s\n", t.Tag)
+	fmt.Fprintf(&buf, "tagger %s <%s> %d %s\n", t.TaggerName, t.TaggerEmail, t.When.Unix(), t.When.Format("-0700"))
+	buf.WriteString("\n")
+	buf.WriteString(t.Message)
+	if !strings.HasSuffix(t.Message, "\n") {
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+// SplitSignature splits a raw tag object into the signed payload and the
+// signature appended to it. The signature is empty for unsigned tags.
+func SplitSignature(raw []byte) ([]byte, []byte) {
+	for _, header := range []string{pgpSignatureHeader, sshSignatureHeader} {
+		if i := bytes.Index(raw, []byte(header)); i >= 0 {
+			return raw[:i], raw[i:]
+		}
+	}
+	return raw, nil
+}
+
+// SignatureFormat returns the format of signature,
+// SignFormatOpenPGP or SignFormatSSH.
+func SignatureFormat(signature []byte) string {
+	if bytes.HasPrefix(signature, []byte(sshSignatureHeader)) {
+		return SignFormatSSH
+	}
+	return SignFormatOpenPGP
+}
+
+// GPGSigner signs with a gpg compatible program.
+type GPGSigner struct {
+	// Program defaults to "gpg".
+	Program string
+	// Key selects the signing key. The default key is used if it's empty.
+	Key string
+}
+
+func (s GPGSigner) Sign(payload []byte) ([]byte, error) {
+	args := []string{"--status-fd=2", "-bsa"}
+	if s.Key != "" {
+		args = append(args, "-u", s.Key)
+	}
+
+	cmd := exec.Command(program(s.Program, "gpg"), args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	signature, err := cmd.Output()
+	if err != nil {
+		return nil, errors.New("gpg failed to sign the tag. " + err.Error() + "\n" + stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "[GNUPG:] SIG_CREATED ") {
+		return nil, errors.New("gpg failed to sign the tag.\n" + stderr.String())
+	}
+
+	return signature, nil
+}
+
+// GPGVerifier verifies with a gpg compatible program.
+type GPGVerifier struct {
+	// Program defaults to "gpg".
+	Program string
+}
+
+func (v GPGVerifier) Verify(payload []byte, signature []byte) (string, error) {
+	sigFile, err := writeTemp("ver-sig-", signature)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(sigFile)
+
+	cmd := exec.Command(program(v.Program, "gpg"), "--status-fd=1", "--verify", sigFile, "-")
+	cmd.Stdin = bytes.NewReader(payload)
+	status, _ := cmd.Output()
+
+	signer := ""
+	good := false
+	scanner := bufio.NewScanner(bytes.NewReader(status))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), " ", 4)
+		if len(fields) < 2 || fields[0] != "[GNUPG:]" {
+			continue
+		}
+		switch fields[1] {
+		case "GOODSIG":
+			good = true
+			if len(fields) == 4 {
+				signer = fields[3]
+			}
+		case "BADSIG", "ERRSIG", "EXPSIG", "EXPKEYSIG", "REVKEYSIG":
+			return "", errors.New("Bad signature (" + fields[1] + ")")
+		}
+	}
+
+	if !good {
+		return "", errors.New("No good signature found")
+	}
+
+	return signer, nil
+}
+
+// SSHSigner signs with ssh-keygen -Y sign.
+type SSHSigner struct {
+	// Program defaults to "ssh-keygen".
+	Program string
+	// Key is the path of a private key, or a public key whose private key
+	// is held by the ssh agent, either as path or literally
+	// like "ssh-ed25519 AAAA...".
+	Key string
+}
+
+func (s SSHSigner) Sign(payload []byte) ([]byte, error) {
+	if s.Key == "" {
+		return nil, errors.New("No ssh signing key configured. Set user.signingKey or use --signing-key")
+	}
+
+	key := expandHome(strings.TrimPrefix(s.Key, "key::"))
+	args := []string{"-Y", "sign", "-n", "git", "-f", key}
+
+	// a literal public key has to be written to a file for ssh-keygen
+	if strings.HasPrefix(key, "ssh-") || strings.HasPrefix(key, "ecdsa-") || strings.HasPrefix(key, "sk-") {
+		keyFile, err := writeTemp("ver-key-", []byte(key+"\n"))
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(keyFile)
+		args = []string{"-Y", "sign", "-n", "git", "-U", "-f", keyFile}
+	}
+
+	cmd := exec.Command(program(s.Program, "ssh-keygen"), args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	signature, err := cmd.Output()
+	if err != nil {
+		return nil, errors.New("ssh-keygen failed to sign the tag. " + err.Error() + "\n" + stderr.String())
+	}
+
+	return signature, nil
+}
+
+// SSHVerifier verifies with ssh-keygen -Y verify against an allowed signers
+// file, see gpg.ssh.allowedSignersFile in git-config(1).
+type SSHVerifier struct {
+	// Program defaults to "ssh-keygen".
+	Program string
+	// AllowedSigners is the path of the allowed signers file.
+	AllowedSigners string
+}
+
+func (v SSHVerifier) Verify(payload []byte, signature []byte) (string, error) {
+	if v.AllowedSigners == "" {
+		return "", errors.New("No allowed signers file configured. Set gpg.ssh.allowedSignersFile")
+	}
+	allowed := expandHome(v.AllowedSigners)
+
+	sigFile, err := writeTemp("ver-sig-", signature)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(sigFile)
+
+	out, err := exec.Command(program(v.Program, "ssh-keygen"), "-Y", "find-principals", "-f", allowed, "-s", sigFile).Output()
+	if err != nil {
+		return "", errors.New("No allowed signer found for the signature")
+	}
+	principal := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+
+	cmd := exec.Command(program(v.Program, "ssh-keygen"), "-Y", "verify", "-n", "git", "-f", allowed, "-I", principal, "-s", sigFile)
+	cmd.Stdin = bytes.NewReader(payload)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", errors.New("Bad signature. " + strings.TrimSpace(string(out)))
+	}
+
+	return principal, nil
+}
+
+func program(name string, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+func writeTemp(prefix string, data []byte) (string, error) {
+	f, err := ioutil.TempFile("", prefix)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
@@ -0,0 +1,153 @@
+package ver
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	git "gopkg.in/libgit2/git2go.v25"
+)
+
+// createTestKey generates a throwaway ssh key and an allowed signers file
+// trusting it for jane@example.com. It returns the private key, the allowed
+// signers file and the directory to remove.
+func createTestKey(t *testing.T) (string, string, string) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not found")
+	}
+
+	dir, err := ioutil.TempDir("", "ver")
+	checkFatal(t, err)
+
+	key := filepath.Join(dir, "key")
+	out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "test", "-f", key).CombinedOutput()
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("ssh-keygen failed. %s\n%s", err, out)
+	}
+
+	pub, err := ioutil.ReadFile(key + ".pub")
+	checkFatal(t, err)
+
+	allowed := filepath.Join(dir, "allowed_signers")
+	checkFatal(t, ioutil.WriteFile(allowed, append([]byte("jane@example.com "), pub...), 0644))
+
+	return key, allowed, dir
+}
+
+func TestSSHSignature(t *testing.T) {
+	key, allowed, dir := createTestKey(t)
+	defer os.RemoveAll(dir)
+
+	payload := TagObject{
+		Object:      "27c1f1234188aa11585334726f8721d9a35038eb",
+		Type:        "commit",
+		Tag:         "v1.0.0",
+		TaggerName:  testSignature.Name,
+		TaggerEmail: testSignature.Email,
+		When:        testSignature.When,
+		Message:     "v1.0.0\n",
+	}.Bytes()
+
+	signature, err := SSHSigner{Key: key}.Sign(payload)
+	checkFatal(t, err)
+
+	p, s := SplitSignature(append(payload, signature...))
+	if !bytes.Equal(p, payload) || !bytes.Equal(s, signature) {
+		t.Fatal("SplitSignature doesn't return the payload and the signature")
+	}
+	if format := SignatureFormat(s); format != SignFormatSSH {
+		t.Errorf("SignatureFormat() = %q, want %q", format, SignFormatSSH)
+	}
+
+	verifier := SSHVerifier{AllowedSigners: allowed}
+
+	signer, err := verifier.Verify(payload, signature)
+	if err != nil {
+		t.Fatalf("Verify failed: %s", err)
+	}
+	if signer != "jane@example.com" {
+		t.Errorf("signer is %q, want jane@example.com", signer)
+	}
+
+	tampered := bytes.Replace(payload, []byte("v1.0.0"), []byte("v2.0.0"), -1)
+	if _, err := verifier.Verify(tampered, signature); err == nil {
+		t.Error("tampered payload verified")
+	}
+
+	// jane@example.com with another key
+	_, other, otherDir := createTestKey(t)
+	defer os.RemoveAll(otherDir)
+	if _, err := (SSHVerifier{AllowedSigners: other}).Verify(payload, signature); err == nil {
+		t.Error("signature of an unknown key verified")
+	}
+}
+
+func TestCreateSignedTag(t *testing.T) {
+	key, allowed, dir := createTestKey(t)
+	defer os.RemoveAll(dir)
+
+	repo := createTestRepo(t, false)
+	defer cleanupTestRepo(t, repo)
+	commit := commitTestRepo(t, repo, "initial")
+
+	verifiers := map[string]Verifier{SignFormatSSH: SSHVerifier{AllowedSigners: allowed}}
+
+	_, err := CreateTag(repo, "v1.0.0", commit, testSignature, "v1.0.0\n", SSHSigner{Key: key})
+	checkFatal(t, err)
+
+	result, err := VerifyTag(repo, "v1.0.0", verifiers)
+	if err != nil {
+		t.Fatalf("VerifyTag failed: %s", err)
+	}
+	if result.Format != SignFormatSSH || result.Signer != "jane@example.com" {
+		t.Errorf("VerifyTag() = %+v", result)
+	}
+
+	info, err := GetTagInfo(repo, "v1.0.0", DefaultTagFormat)
+	checkFatal(t, err)
+	if !info.Annotated || !info.Signed || info.Message != "v1.0.0\n" || !info.Commit.Id().Equal(commit.Id()) {
+		t.Errorf("GetTagInfo() = %+v", info)
+	}
+
+	if _, err := CreateTag(repo, "v1.0.0", commit, testSignature, "again\n", SSHSigner{Key: key}); err == nil {
+		t.Error("existing tag overwritten")
+	}
+
+	// the same tag object with another message
+	odb, err := repo.Odb()
+	checkFatal(t, err)
+	defer odb.Free()
+
+	ref, err := repo.References.Lookup("refs/tags/v1.0.0")
+	checkFatal(t, err)
+	defer ref.Free()
+
+	obj, err := odb.Read(ref.Target())
+	checkFatal(t, err)
+	data := append([]byte{}, obj.Data()...)
+	obj.Free()
+
+	tampered := bytes.Replace(data, []byte("\n\nv1.0.0\n"), []byte("\n\nv1.0.1\n"), 1)
+	if bytes.Equal(tampered, data) {
+		t.Fatal("message not found in tag object")
+	}
+	id, err := odb.Write(tampered, git.ObjectTag)
+	checkFatal(t, err)
+	tamperedRef, err := repo.References.Create("refs/tags/v1.0.1", id, false, "")
+	checkFatal(t, err)
+	tamperedRef.Free()
+
+	if _, err := VerifyTag(repo, "v1.0.1", verifiers); err == nil {
+		t.Error("tampered tag verified")
+	}
+
+	_, err = CreateTag(repo, "v1.1.0", commit, testSignature, "v1.1.0\n", nil)
+	checkFatal(t, err)
+	if _, err := VerifyTag(repo, "v1.1.0", verifiers); err == nil {
+		t.Error("unsigned tag verified")
+	}
+}
@@ -0,0 +1,43 @@
+package ver
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// SourceTags selects the git tags as version source.
+const SourceTags = "tags"
+
+// VersionSource provides the existing versions of a repository.
+type VersionSource interface {
+	Versions() (Versions, error)
+	// String describes the source, e.g. "tags" or "package.json".
+	String() string
+}
+
+// FileSource reads the version from a VERSION file or a manifest like
+// package.json. It provides a single version.
+type FileSource struct {
+	// Dir is the directory File.Path is relative to.
+	Dir  string
+	File VersionFile
+}
+
+func (s FileSource) Versions() (Versions, error) {
+	content, err := ioutil.ReadFile(filepath.Join(s.Dir, s.File.Path))
+	if err != nil {
+		return nil, errors.New("Unable to read version file. " + err.Error())
+	}
+
+	v, err := FileVersion(s.File, content)
+	if err != nil {
+		return nil, err
+	}
+
+	return Versions{v}, nil
+}
+
+func (s FileSource) String() string {
+	return s.File.Path
+}
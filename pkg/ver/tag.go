@@ -0,0 +1,274 @@
+package ver
+
+import (
+	"errors"
+	"sort"
+
+	git "gopkg.in/libgit2/git2go.v25"
+)
+
+// CreateTag creates the annotated tag name for commit.
+// With a signer the tag object is built and signed here,
+// since libgit2 can't sign tags itself.
+func CreateTag(repo *git.Repository, name string, commit *git.Commit, tagger *git.Signature, message string, signer Signer) (*git.Oid, error) {
+	if signer == nil {
+		return repo.Tags.Create(name, commit, tagger, message)
+	}
+
+	if _, err := repo.References.Lookup("refs/tags/" + name); err == nil {
+		return nil, errors.New("Tag `" + name + "` already exists")
+	}
+
+	payload := TagObject{
+		Object:      commit.Id().String(),
+		Type:        "commit",
+		Tag:         name,
+		TaggerName:  tagger.Name,
+		TaggerEmail: tagger.Email,
+		When:        tagger.When,
+		Message:     message,
+	}.Bytes()
+
+	signature, err := signer.Sign(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	odb, err := repo.Odb()
+	if err != nil {
+		return nil, err
+	}
+	defer odb.Free()
+
+	id, err := odb.Write(append(payload, signature...), git.ObjectTag)
+	if err != nil {
+		return nil, errors.New("Unable to write tag object. " + err.Error())
+	}
+
+	ref, err := repo.References.Create("refs/tags/"+name, id, false, "")
+	if err != nil {
+		return nil, err
+	}
+	ref.Free()
+
+	return id, nil
+}
+
+// TagSignature is the result of verifying a tag.
+type TagSignature struct {
+	Tag    string
+	Format string
+	Signer string
+}
+
+// VerifyTag checks the signature of the annotated tag name.
+// verifiers maps signature formats to the verifier to use.
+func VerifyTag(repo *git.Repository, name string, verifiers map[string]Verifier) (TagSignature, error) {
+	result := TagSignature{Tag: name}
+
+	ref, err := repo.References.Lookup("refs/tags/" + name)
+	if err != nil {
+		return result, errors.New("Couldn't find tag `" + name + "`. " + err.Error())
+	}
+	defer ref.Free()
+
+	odb, err := repo.Odb()
+	if err != nil {
+		return result, err
+	}
+	defer odb.Free()
+
+	obj, err := odb.Read(ref.Target())
+	if err != nil {
+		return result, err
+	}
+	defer obj.Free()
+
+	if obj.Type() != git.ObjectTag {
+		return result, errors.New("Tag `" + name + "` is a lightweight tag and can't be signed")
+	}
+
+	payload, signature := SplitSignature(obj.Data())
+	if len(signature) == 0 {
+		return result, errors.New("Tag `" + name + "` is not signed")
+	}
+
+	result.Format = SignatureFormat(signature)
+	verifier, ok := verifiers[result.Format]
+	if !ok {
+		return result, errors.New("Can't verify " + result.Format + " signatures")
+	}
+
+	result.Signer, err = verifier.Verify(payload, signature)
+	if err != nil {
+		return result, errors.New("Signature of tag `" + name + "` is invalid. " + err.Error())
+	}
+
+	return result, nil
+}
+
+// SigningConfig is the signing setup read from the git config.
+type SigningConfig struct {
+	// Sign is tag.gpgSign.
+	Sign bool
+	// Format is gpg.format, SignFormatOpenPGP by default.
+	Format string
+	// Key is user.signingKey.
+	Key string
+	// Program is gpg.program.
+	Program string
+	// SSHProgram is gpg.ssh.program.
+	SSHProgram string
+	// AllowedSigners is gpg.ssh.allowedSignersFile.
+	AllowedSigners string
+}
+
+// GetSigningConfig reads the signing setup from the git config of repo.
+// Missing keys are left empty.
+func GetSigningConfig(repo *git.Repository) (SigningConfig, error) {
+	sc := SigningConfig{Format: SignFormatOpenPGP}
+
+	conf, err := repo.Config()
+	if err != nil {
+		return sc, errors.New("Couldn't load git config. " + err.Error())
+	}
+	defer conf.Free()
+
+	sc.Sign, _ = conf.LookupBool("tag.gpgSign")
+	if format, _ := conf.LookupString("gpg.format"); format != "" {
+		sc.Format = format
+	}
+	sc.Key, _ = conf.LookupString("user.signingKey")
+	sc.Program, _ = conf.LookupString("gpg.program")
+	sc.SSHProgram, _ = conf.LookupString("gpg.ssh.program")
+	sc.AllowedSigners, _ = conf.LookupString("gpg.ssh.allowedSignersFile")
+
+	return sc, nil
+}
+
+// Signer returns the signer for the configured format.
+func (sc SigningConfig) Signer() (Signer, error) {
+	switch sc.Format {
+	case SignFormatOpenPGP:
+		return GPGSigner{Program: sc.Program, Key: sc.Key}, nil
+	case SignFormatSSH:
+		return SSHSigner{Program: sc.SSHProgram, Key: sc.Key}, nil
+	}
+	return nil, errors.New("Unsupported signature format `" + sc.Format + "`")
+}
+
+// Verifiers returns a verifier for every supported format.
+func (sc SigningConfig) Verifiers() map[string]Verifier {
+	return map[string]Verifier{
+		SignFormatOpenPGP: GPGVerifier{Program: sc.Program},
+		SignFormatSSH:     SSHVerifier{Program: sc.SSHProgram, AllowedSigners: sc.AllowedSigners},
+	}
+}
+
+// TagInfo describes a version tag.
+type TagInfo struct {
+	Name    string
+	Version Version
+	// Annotated is false for lightweight tags,
+	// which have no tag id, tagger and message.
+	Annotated bool
+	TagId     *git.Oid
+	Tagger    *git.Signature
+	Message   string
+	Signed    bool
+	// Commit is the commit the tag points to, peeled through annotated tags.
+	Commit *git.Commit
+}
+
+// GetTagInfo describes the tag name of a version in format.
+func GetTagInfo(repo *git.Repository, name string, format TagFormat) (TagInfo, error) {
+	info := TagInfo{Name: name}
+
+	v, err := format.Parse(name)
+	if err != nil {
+		return info, err
+	}
+	info.Version = v
+
+	ref, err := repo.References.Lookup("refs/tags/" + name)
+	if err != nil {
+		return info, errors.New("Couldn't find tag `" + name + "`. " + err.Error())
+	}
+	defer ref.Free()
+
+	obj, err := repo.Lookup(ref.Target())
+	if err != nil {
+		return info, err
+	}
+	defer obj.Free()
+
+	if obj.Type() == git.ObjectTag {
+		tag, err := obj.AsTag()
+		if err != nil {
+			return info, err
+		}
+
+		info.Annotated = true
+		info.TagId = tag.Id()
+		info.Tagger = tag.Tagger()
+		// libgit2 keeps the signature in the message
+		message, signature := SplitSignature([]byte(tag.Message()))
+		info.Message = string(message)
+		info.Signed = len(signature) > 0
+	}
+
+	info.Commit, err = GetTagCommit(repo, name)
+	if err != nil {
+		return info, err
+	}
+
+	return info, nil
+}
+
+// GetVersionTags describes all tags of versions in format of component,
+// sorted by version.
+func GetVersionTags(repo *git.Repository, component string, format TagFormat) ([]TagInfo, error) {
+	tags, err := repo.Tags.List()
+	if err != nil {
+		return nil, errors.New("Tags could not be loaded. " + err.Error())
+	}
+
+	infos := []TagInfo{}
+	for _, tag := range tags {
+		namespace, _ := SplitComponent(tag)
+		if namespace != component {
+			continue
+		}
+		if _, err := format.Parse(tag); err != nil {
+			continue
+		}
+
+		info, err := GetTagInfo(repo, tag, format)
+		if err != nil {
+			// tags of trees or blobs
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].Version.LessThan(infos[j].Version)
+	})
+
+	return infos, nil
+}
+
+// TagSource provides the versions of the tags of Component.
+type TagSource struct {
+	Repo      *git.Repository
+	Component string
+	Format    TagFormat
+}
+
+func (s TagSource) Versions() (Versions, error) {
+	return GetVersions(s.Repo, s.Component, s.Format)
+}
+
+func (s TagSource) String() string {
+	return SourceTags
+}
@@ -2,40 +2,59 @@ package ver
 
 import (
 	"errors"
+	"os"
 	"time"
 
 	git "gopkg.in/libgit2/git2go.v25"
 )
 
-func GetGitUser() (*git.Signature, error) {
-	confPath, err := git.ConfigFindGlobal()
+// GetGitUser returns the identity tags are created with.
+// Like git it prefers GIT_COMMITTER_NAME and GIT_COMMITTER_EMAIL over user.name
+// and user.email from the repository, global and system config, and
+// GIT_COMMITTER_DATE over the current time. A non empty name or email
+// given here overrides all of them.
+func GetGitUser(repo *git.Repository, name string, email string) (*git.Signature, error) {
+	conf, err := repo.Config()
 	if err != nil {
-		return nil, errors.New("Couldn't locate git config. " + err.Error())
+		return nil, errors.New("Couldn't load git config. " + err.Error())
 	}
+	defer conf.Free()
 
-	simpleConf, err := git.NewConfig()
-	if err != nil {
-		return nil, err
+	if name == "" {
+		name = os.Getenv("GIT_COMMITTER_NAME")
 	}
-
-	conf, err := git.OpenOndisk(simpleConf, confPath)
-	if err != nil {
-		return nil, err
+	if name == "" {
+		name, _ = conf.LookupString("user.name")
+	}
+	if name == "" {
+		return nil, errors.New("Couldn't find user.name git config key. Set it, GIT_COMMITTER_NAME or use --tagger-name")
 	}
 
-	name, err := conf.LookupString("user.name")
-	if err != nil {
-		return nil, errors.New("Couldn't find user.name git config key. " + err.Error())
+	if email == "" {
+		email = os.Getenv("GIT_COMMITTER_EMAIL")
 	}
-	email, err := conf.LookupString("user.email")
-	if err != nil {
-		return nil, errors.New("Couldn't find user.email git config key. " + err.Error())
+	if email == "" {
+		email, _ = conf.LookupString("user.email")
+	}
+	if email == "" {
+		email = os.Getenv("EMAIL")
+	}
+	if email == "" {
+		return nil, errors.New("Couldn't find user.email git config key. Set it, GIT_COMMITTER_EMAIL or use --tagger-email")
+	}
+
+	when := time.Now()
+	if date := os.Getenv("GIT_COMMITTER_DATE"); date != "" {
+		when, err = ParseGitDate(date)
+		if err != nil {
+			return nil, errors.New("Invalid GIT_COMMITTER_DATE. " + err.Error())
+		}
 	}
 
 	user := &git.Signature{
 		Name:  name,
 		Email: email,
-		When:  time.Now(),
+		When:  when,
 	}
 
 	return user, nil
@@ -56,3 +75,386 @@ func GetHeadCommit(repo *git.Repository) (*git.Commit, error) {
 
 	return commit, nil
 }
+
+// GetVersions returns the versions of all tags in the namespace of component
+// which can be parsed as a version in format. Other tags are ignored.
+// An empty component selects the tags without namespace.
+func GetVersions(repo *git.Repository, component string, format TagFormat) (Versions, error) {
+	tags, err := repo.Tags.List()
+	if err != nil {
+		return nil, errors.New("Tags could not be loaded. " + err.Error())
+	}
+
+	versions := Versions{}
+	for _, tag := range tags {
+		namespace, _ := SplitComponent(tag)
+		if namespace != component {
+			continue
+		}
+
+		v, err := format.Parse(tag)
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, v)
+	}
+
+	return versions, nil
+}
+
+// GetTagCommit returns the commit the tag name points to.
+// Annotated tags are peeled to their target commit.
+func GetTagCommit(repo *git.Repository, name string) (*git.Commit, error) {
+	ref, err := repo.References.Lookup("refs/tags/" + name)
+	if err != nil {
+		return nil, errors.New("Couldn't find tag `" + name + "`. " + err.Error())
+	}
+
+	obj, err := ref.Peel(git.ObjectCommit)
+	if err != nil {
+		return nil, errors.New("Tag `" + name + "` doesn't point to a commit. " + err.Error())
+	}
+
+	return obj.AsCommit()
+}
+
+// GetCommitsSince returns all commits reachable from HEAD but not from since,
+// newest first. If since is nil all commits reachable from HEAD are returned.
+func GetCommitsSince(repo *git.Repository, since *git.Oid) ([]*git.Commit, error) {
+	head, err := GetHeadCommit(repo)
+	if err != nil {
+		return nil, errors.New("Unable to resolve HEAD. " + err.Error())
+	}
+
+	return GetCommitsBetween(repo, since, head.Id())
+}
+
+// GetCommitsBetween returns all commits reachable from to but not from from,
+// newest first. If from is nil all commits reachable from to are returned.
+func GetCommitsBetween(repo *git.Repository, from, to *git.Oid) ([]*git.Commit, error) {
+	walk, err := repo.Walk()
+	if err != nil {
+		return nil, err
+	}
+	defer walk.Free()
+
+	walk.Sorting(git.SortTopological | git.SortTime)
+
+	if err := walk.Push(to); err != nil {
+		return nil, errors.New("Unable to walk commits from `" + to.String() + "`. " + err.Error())
+	}
+
+	if from != nil {
+		if err := walk.Hide(from); err != nil {
+			return nil, err
+		}
+	}
+
+	commits := []*git.Commit{}
+	err = walk.Iterate(func(c *git.Commit) bool {
+		commits = append(commits, c)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return commits, nil
+}
+
+// CommitTouches reports whether commit changes anything below path.
+// Like `git log -- path` a merge only counts if it differs from all
+// of its parents below path.
+func CommitTouches(repo *git.Repository, commit *git.Commit, path string) (bool, error) {
+	opts, err := git.DefaultDiffOptions()
+	if err != nil {
+		return false, err
+	}
+	opts.Pathspec = []string{path}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return false, err
+	}
+
+	if commit.ParentCount() == 0 {
+		return diffHasDeltas(repo, nil, tree, &opts)
+	}
+
+	for i := uint(0); i < commit.ParentCount(); i++ {
+		// missing in shallow clones
+		parent := commit.Parent(i)
+		if parent == nil {
+			return false, errors.New("Parent " + commit.ParentId(i).String() + " of commit " + commit.Id().String() + " is missing. Fetch more history if this is a shallow clone")
+		}
+
+		parentTree, err := parent.Tree()
+		if err != nil {
+			return false, err
+		}
+
+		changed, err := diffHasDeltas(repo, parentTree, tree, &opts)
+		if err != nil || !changed {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func diffHasDeltas(repo *git.Repository, oldTree, newTree *git.Tree, opts *git.DiffOptions) (bool, error) {
+	diff, err := repo.DiffTreeToTree(oldTree, newTree, opts)
+	if err != nil {
+		return false, err
+	}
+	defer diff.Free()
+
+	n, err := diff.NumDeltas()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+// FilterCommitsByPath returns the commits which change anything below path.
+func FilterCommitsByPath(repo *git.Repository, commits []*git.Commit, path string) ([]*git.Commit, error) {
+	filtered := []*git.Commit{}
+	for _, c := range commits {
+		touches, err := CommitTouches(repo, c, path)
+		if err != nil {
+			return nil, errors.New("Unable to diff commit `" + c.Id().String() + "`. " + err.Error())
+		}
+		if touches {
+			filtered = append(filtered, c)
+		}
+	}
+
+	return filtered, nil
+}
+
+// IsDirty reports whether the index or the working tree contain changes
+// to tracked files. Untracked files don't count.
+func IsDirty(repo *git.Repository) (bool, error) {
+	list, err := repo.StatusList(&git.StatusOptions{
+		Show:  git.StatusShowIndexAndWorkdir,
+		Flags: git.StatusOptExcludeSubmodules,
+	})
+	if err != nil {
+		return false, errors.New("Unable to get status of working tree. " + err.Error())
+	}
+	defer list.Free()
+
+	n, err := list.EntryCount()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+// GetTagsAt returns the names of all tags pointing to the commit id.
+// Annotated tags are peeled to their target commit.
+func GetTagsAt(repo *git.Repository, id *git.Oid) ([]string, error) {
+	tags, err := repo.Tags.List()
+	if err != nil {
+		return nil, errors.New("Tags could not be loaded. " + err.Error())
+	}
+
+	at := []string{}
+	for _, tag := range tags {
+		c, err := GetTagCommit(repo, tag)
+		if err != nil {
+			// tags of trees or blobs
+			continue
+		}
+		if c.Id().Equal(id) {
+			at = append(at, tag)
+		}
+	}
+
+	return at, nil
+}
+
+// GetCurrentBranch returns the short name of the branch HEAD points to.
+// It fails if HEAD is detached.
+func GetCurrentBranch(repo *git.Repository) (string, error) {
+	detached, err := repo.IsHeadDetached()
+	if err != nil {
+		return "", err
+	}
+	if detached {
+		return "", errors.New("HEAD is detached")
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+
+	return head.Shorthand(), nil
+}
+
+// describeCandidates is the number of tags compared to find the nearest one,
+// like the default of `git describe --candidates`.
+const describeCandidates = 10
+
+// Describe describes HEAD relative to the nearest version tag of component
+// in format like git describe. Only tags which GetVersions accepts are
+// considered, other namespaces and tags which aren't versions are skipped.
+func Describe(repo *git.Repository, component string, format TagFormat) (Description, error) {
+	head, err := GetHeadCommit(repo)
+	if err != nil {
+		return Description{}, errors.New("Unable to resolve HEAD. " + err.Error())
+	}
+
+	versions, err := GetVersions(repo, component, format)
+	if err != nil {
+		return Description{}, err
+	}
+
+	// the highest version tagged on each commit
+	tagged := map[git.Oid]Version{}
+	for _, v := range versions {
+		commit, err := GetTagCommit(repo, v.Tag)
+		if err != nil {
+			continue
+		}
+		if t, ok := tagged[*commit.Id()]; !ok || v.Compare(t) > 0 {
+			tagged[*commit.Id()] = v
+		}
+	}
+
+	candidates, err := describeCandidatesOf(repo, head.Id(), tagged)
+	if err != nil {
+		return Description{}, err
+	}
+
+	d := Description{Hash: head.Id().String()[:7], Distance: -1}
+	for _, id := range candidates {
+		commits, err := GetCommitsBetween(repo, id, head.Id())
+		if err != nil {
+			return Description{}, err
+		}
+		if d.Distance < 0 || len(commits) < d.Distance {
+			d.Tag = tagged[*id].Tag
+			d.Version = tagged[*id]
+			d.Distance = len(commits)
+		}
+	}
+
+	// without a tag the distance is the number of commits up to HEAD
+	if d.Tag == "" {
+		commits, err := GetCommitsBetween(repo, nil, head.Id())
+		if err != nil {
+			return Description{}, err
+		}
+		d.Distance = len(commits)
+	}
+
+	d.Dirty, err = IsDirty(repo)
+	if err != nil {
+		return Description{}, err
+	}
+
+	return d, nil
+}
+
+// describeCandidatesOf returns the first tagged commits reachable from head.
+func describeCandidatesOf(repo *git.Repository, head *git.Oid, tagged map[git.Oid]Version) ([]*git.Oid, error) {
+	candidates := []*git.Oid{}
+	if len(tagged) == 0 {
+		return candidates, nil
+	}
+
+	walk, err := repo.Walk()
+	if err != nil {
+		return nil, err
+	}
+	defer walk.Free()
+
+	walk.Sorting(git.SortTopological | git.SortTime)
+	if err := walk.Push(head); err != nil {
+		return nil, err
+	}
+
+	err = walk.Iterate(func(c *git.Commit) bool {
+		if _, ok := tagged[*c.Id()]; ok {
+			candidates = append(candidates, c.Id())
+		}
+		return len(candidates) < describeCandidates
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return candidates, nil
+}
+
+// ResolveCommit resolves the revision rev, e.g. "HEAD~3", "main", "27c1f12"
+// or a tag, to the commit it names.
+func ResolveCommit(repo *git.Repository, rev string) (*git.Commit, error) {
+	obj, err := repo.RevparseSingle(rev)
+	if err != nil {
+		return nil, errors.New("Unable to resolve `" + rev + "`. " + err.Error())
+	}
+
+	commit, err := obj.Peel(git.ObjectCommit)
+	if err != nil {
+		return nil, errors.New("`" + rev + "` doesn't point to a commit. " + err.Error())
+	}
+
+	return commit.AsCommit()
+}
+
+// IsAncestor reports whether ancestor is reachable from commit,
+// which includes commit itself.
+func IsAncestor(repo *git.Repository, commit, ancestor *git.Oid) (bool, error) {
+	if commit.Equal(ancestor) {
+		return true, nil
+	}
+	return repo.DescendantOf(commit, ancestor)
+}
+
+// CommitFiles commits the current content of paths, relative to the
+// repository root, on top of HEAD and moves HEAD to the new commit.
+// Other changes in the index are committed as well.
+func CommitFiles(repo *git.Repository, paths []string, author *git.Signature, message string) (*git.Commit, error) {
+	index, err := repo.Index()
+	if err != nil {
+		return nil, err
+	}
+	defer index.Free()
+
+	for _, path := range paths {
+		if err := index.AddByPath(path); err != nil {
+			return nil, errors.New("Unable to stage `" + path + "`. " + err.Error())
+		}
+	}
+	if err := index.Write(); err != nil {
+		return nil, err
+	}
+
+	treeId, err := index.WriteTree()
+	if err != nil {
+		return nil, err
+	}
+	tree, err := repo.LookupTree(treeId)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Free()
+
+	head, err := GetHeadCommit(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := repo.CreateCommit("HEAD", author, author, message, tree, head)
+	if err != nil {
+		return nil, errors.New("Unable to commit. " + err.Error())
+	}
+
+	return repo.LookupCommit(id)
+}
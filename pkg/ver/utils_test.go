@@ -0,0 +1,60 @@
+package ver
+
+import (
+	"testing"
+
+	git "gopkg.in/libgit2/git2go.v25"
+)
+
+func TestDescribeSkipsOtherTags(t *testing.T) {
+	repo := createTestRepo(t, false)
+	defer cleanupTestRepo(t, repo)
+
+	tag := func(name string, target *git.Commit) {
+		_, err := repo.Tags.CreateLightweight(name, target, false)
+		checkFatal(t, err)
+	}
+
+	first := commitTestRepo(t, repo, "first")
+	tag("1.2.3", first)
+	tag("api/v0.1.0", first)
+
+	second := commitTestRepo(t, repo, "second")
+	tag("services/api/v3.0.0", second)
+	tag("api/internal/v9.0.0", second)
+	tag("latest", second)
+	tag("deploy-2024", second)
+
+	head := commitTestRepo(t, repo, "third")
+
+	format := TagFormat{Prefix: "v", Alternatives: []string{""}}
+
+	tests := []struct {
+		component string
+		tag       string
+		version   string
+	}{
+		{"", "1.2.3", "1.2.3"},
+		{"api", "api/v0.1.0", "0.1.0"},
+	}
+
+	for _, test := range tests {
+		d, err := Describe(repo, test.component, format)
+		if err != nil {
+			t.Errorf("Describe(%q) failed: %s", test.component, err)
+			continue
+		}
+		if d.Tag != test.tag || d.Version.String() != test.version || d.Distance != 2 {
+			t.Errorf("Describe(%q) = %+v, want %s at distance 2", test.component, d, test.tag)
+		}
+		if d.Hash != head.Id().String()[:7] {
+			t.Errorf("Describe(%q) has hash %q, want %s", test.component, d.Hash, head.Id())
+		}
+	}
+
+	d, err := Describe(repo, "web", format)
+	checkFatal(t, err)
+	if d.Tag != "" || d.Distance != 3 {
+		t.Errorf("Describe(web) = %+v, want no tag at distance 3", d)
+	}
+}
@@ -4,51 +4,151 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
 
-var Prefix string
-
+// Version is a semantic version as described by https://semver.org/spec/v2.0.0.html
+// Prerelease and Metadata hold the dot separated identifiers without
+// the leading "-" and "+".
 type Version struct {
-	Major int
-	Minor int
-	Patch int
-	build string
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	Metadata   string
+	// Tag is the name of the tag the version was parsed from.
+	// It's empty for versions not read from a tag.
+	Tag string
 }
 
+// String returns v without any prefix, e.g. "1.2.0-rc.1".
+// Use TagFormat to get its tag name.
 func (v Version) String() string {
-	if v.build != "" {
-		return fmt.Sprintf("%s%d.%d.%d-%s", Prefix, v.Major, v.Minor, v.Patch, v.build)
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Metadata != "" {
+		s += "+" + v.Metadata
 	}
-	return fmt.Sprintf("%s%d.%d.%d", Prefix, v.Major, v.Minor, v.Patch)
+	return s
+}
+
+// IsPrerelease reports whether v has a prerelease part.
+func (v Version) IsPrerelease() bool {
+	return v.Prerelease != ""
 }
 
+// Compare returns -1, 0 or +1 depending on whether v has a lower, equal
+// or higher precedence than o. Build metadata is ignored.
+func (v Version) Compare(o Version) int {
+	if c := compareInt(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+
+	// a version without prerelease has a higher precedence
+	switch {
+	case v.Prerelease == o.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case o.Prerelease == "":
+		return -1
+	}
+
+	a := strings.Split(v.Prerelease, ".")
+	b := strings.Split(o.Prerelease, ".")
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareIdentifier(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+
+	return compareInt(len(a), len(b))
+}
+
+// LessThan reports whether v has a lower precedence than o.
+func (v Version) LessThan(o Version) bool {
+	return v.Compare(o) < 0
+}
+
+// GreaterThan reports whether v has a higher precedence than o.
+func (v Version) GreaterThan(o Version) bool {
+	return v.Compare(o) > 0
+}
+
+// Equal reports whether v and o have the same precedence.
+func (v Version) Equal(o Version) bool {
+	return v.Compare(o) == 0
+}
+
+// Versions implements sort.Interface ordering by precedence.
+// Versions with equal precedence keep their original order when sorted
+// with sort.Stable.
 type Versions []Version
 
-func (versions Versions) Latest() Version {
-	var latest Version
+func (versions Versions) Len() int           { return len(versions) }
+func (versions Versions) Less(i, j int) bool { return versions[i].Compare(versions[j]) < 0 }
+func (versions Versions) Swap(i, j int)      { versions[i], versions[j] = versions[j], versions[i] }
+
+// Sorted returns a sorted copy of versions, lowest precedence first.
+func (versions Versions) Sorted() Versions {
+	sorted := make(Versions, len(versions))
+	copy(sorted, versions)
+	sort.Stable(sorted)
+	return sorted
+}
+
+// Max returns the version with the highest precedence.
+// If several versions share it the first one wins.
+// An empty list yields the zero Version.
+func (versions Versions) Max() Version {
+	var max Version
 	for i, v := range versions {
-		if i == 0 {
-			latest = v
+		if i == 0 || v.Compare(max) > 0 {
+			max = v
 		}
+	}
 
-		if v.Major > latest.Major {
-			latest = v
-		}
-		if (v.Major == latest.Major) && (v.Minor > latest.Minor) {
-			latest = v
-		}
-		if (v.Major == latest.Major) && (v.Minor == latest.Minor) && (v.Patch > latest.Patch) {
-			latest = v
+	return max
+}
+
+// Min returns the version with the lowest precedence.
+// If several versions share it the first one wins.
+// An empty list yields the zero Version.
+func (versions Versions) Min() Version {
+	var min Version
+	for i, v := range versions {
+		if i == 0 || v.Compare(min) < 0 {
+			min = v
 		}
+	}
 
-		if (v.Major >= latest.Major) && (v.Minor >= latest.Minor) && (v.Patch >= latest.Patch) {
-			latest = v
+	return min
+}
+
+// Filter returns all versions for which fn returns true.
+func (versions Versions) Filter(fn func(Version) bool) Versions {
+	filtered := Versions{}
+	for _, v := range versions {
+		if fn(v) {
+			filtered = append(filtered, v)
 		}
 	}
 
-	return latest
+	return filtered
+}
+
+func (versions Versions) Latest() Version {
+	return versions.Max()
 }
 
 func CheckError(err error) {
@@ -58,70 +158,179 @@ func CheckError(err error) {
 	}
 }
 
-func toVersion(s string) (*Version, error) {
-	tmp := strings.Split(s, ".")
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
 
-	switch len(tmp) {
-	case 1:
-		tmp = []string{
-			tmp[0],
-			"0",
-			"0",
+// compareIdentifier compares two prerelease identifiers.
+// Numeric identifiers are compared numerically and always have a lower
+// precedence than alphanumeric ones, which are compared lexically.
+func compareIdentifier(a, b string) int {
+	an, bn := isNumeric(a), isNumeric(b)
+	switch {
+	case an && bn:
+		// no leading zeros, so the longer one is bigger
+		if c := compareInt(len(a), len(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	case an:
+		return -1
+	case bn:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
 		}
-	case 2:
-		tmp = []string{
-			tmp[0],
-			tmp[1],
-			"0",
+	}
+	return true
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-') {
+			return false
 		}
 	}
+	return true
+}
 
-	major, err := strconv.Atoi(tmp[0])
+func parseNumber(name, s string) (int, error) {
+	if !isNumeric(s) {
+		return 0, errors.New(name + " has to be an int. Got `" + s + "`")
+	}
+	if len(s) > 1 && s[0] == '0' {
+		return 0, errors.New(name + " must not contain leading zeros. Got `" + s + "`")
+	}
+	n, err := strconv.Atoi(s)
 	if err != nil {
-		return nil, errors.New("Major has to be an int. " + err.Error())
+		return 0, errors.New(name + " has to be an int. " + err.Error())
 	}
+	return n, nil
+}
 
-	minor, err := strconv.Atoi(tmp[1])
-	if err != nil {
-		return nil, errors.New("Minor has to be an int. " + err.Error())
+// ValidatePrerelease checks that s is a valid prerelease part
+// without the leading "-".
+func ValidatePrerelease(s string) error {
+	for _, id := range strings.Split(s, ".") {
+		if !isIdentifier(id) {
+			return errors.New("Invalid prerelease identifier `" + id + "`")
+		}
+		if isNumeric(id) && len(id) > 1 && id[0] == '0' {
+			return errors.New("Numeric prerelease identifier must not contain leading zeros. Got `" + id + "`")
+		}
 	}
+	return nil
+}
 
-	tmp = strings.Split(tmp[2], "-")
+func validateMetadata(s string) error {
+	for _, id := range strings.Split(s, ".") {
+		if !isIdentifier(id) {
+			return errors.New("Invalid build metadata identifier `" + id + "`")
+		}
+	}
+	return nil
+}
 
-	patch, err := strconv.Atoi(tmp[0])
-	if err != nil {
-		return nil, errors.New("Patch has to be an int. " + err.Error())
+// Parse parses a strict SemVer 2.0.0 version string without prefix,
+// e.g. "1.2.3-rc.1+build.5".
+func Parse(s string) (*Version, error) {
+	return parse(s, false)
+}
+
+func parse(s string, lenient bool) (*Version, error) {
+	var prerelease, metadata string
+	hasPrerelease, hasMetadata := false, false
+
+	if i := strings.Index(s, "+"); i >= 0 {
+		s, metadata = s[:i], s[i+1:]
+		hasMetadata = true
+	}
+	if i := strings.Index(s, "-"); i >= 0 {
+		s, prerelease = s[:i], s[i+1:]
+		hasPrerelease = true
 	}
 
-	var build string
-	if len(tmp) == 2 {
-		build = tmp[1]
-	} else {
-		build = ""
+	tmp := strings.Split(s, ".")
+
+	if lenient {
+		switch len(tmp) {
+		case 1:
+			tmp = []string{
+				tmp[0],
+				"0",
+				"0",
+			}
+		case 2:
+			tmp = []string{
+				tmp[0],
+				tmp[1],
+				"0",
+			}
+		}
 	}
 
-	v := &Version{
-		Major: major,
-		Minor: minor,
-		Patch: patch,
-		build: build,
+	if len(tmp) != 3 {
+		return nil, errors.New("Version has to consist of major, minor and patch. Got `" + s + "`")
 	}
 
-	return v, nil
-}
+	major, err := parseNumber("Major", tmp[0])
+	if err != nil {
+		return nil, err
+	}
 
-func cleanTag(t string) string {
-	s := strings.Split(t, "/")
-	tmp := s[len(s)-1]
-	s = strings.Split(tmp, Prefix)
-	return s[len(s)-1]
-}
+	minor, err := parseNumber("Minor", tmp[1])
+	if err != nil {
+		return nil, err
+	}
 
-func GetVersionFromTag(s string) (*Version, error) {
-	tag := cleanTag(s)
-	v, err := toVersion(tag)
+	patch, err := parseNumber("Patch", tmp[2])
 	if err != nil {
 		return nil, err
 	}
+
+	if hasPrerelease {
+		if err := ValidatePrerelease(prerelease); err != nil {
+			return nil, err
+		}
+	}
+
+	if hasMetadata {
+		if err := validateMetadata(metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	v := &Version{
+		Major:      major,
+		Minor:      minor,
+		Patch:      patch,
+		Prerelease: prerelease,
+		Metadata:   metadata,
+	}
+
 	return v, nil
 }
+
+// toVersion parses a version but still accepts the short forms
+// `1` and `1.2` which are treated as `1.0.0` and `1.2.0`.
+func toVersion(s string) (*Version, error) {
+	return parse(s, true)
+}
@@ -0,0 +1,198 @@
+package ver
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+	}{
+		{"0.0.0", Version{}},
+		{"1.2.3", Version{Major: 1, Minor: 2, Patch: 3}},
+		{"10.20.30", Version{Major: 10, Minor: 20, Patch: 30}},
+		{"1.0.0-alpha", Version{Major: 1, Prerelease: "alpha"}},
+		{"1.0.0-alpha.1", Version{Major: 1, Prerelease: "alpha.1"}},
+		{"1.0.0-0.3.7", Version{Major: 1, Prerelease: "0.3.7"}},
+		{"1.0.0-x-y-z.--", Version{Major: 1, Prerelease: "x-y-z.--"}},
+		{"1.0.0+20130313144700", Version{Major: 1, Metadata: "20130313144700"}},
+		{"1.0.0-beta+exp.sha.5114f85", Version{Major: 1, Prerelease: "beta", Metadata: "exp.sha.5114f85"}},
+		{"1.0.0+21AF26D3----117B344092BD", Version{Major: 1, Metadata: "21AF26D3----117B344092BD"}},
+	}
+
+	for _, test := range tests {
+		v, err := Parse(test.in)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %s", test.in, err)
+			continue
+		}
+		if *v != test.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", test.in, *v, test.want)
+		}
+		if v.String() != test.in {
+			t.Errorf("Parse(%q).String() = %q", test.in, v.String())
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"1",
+		"1.2",
+		"1.2.3.4",
+		"01.2.3",
+		"1.02.3",
+		"1.2.03",
+		"1.2.3-01",
+		"1.2.3-",
+		"1.2.3+",
+		"1.2.3-a..b",
+		"1.2.3-a_b",
+		"1.2.3+a_b",
+		"-1.2.3",
+		"a.b.c",
+		"v1.2.3",
+	}
+
+	for _, in := range invalid {
+		if v, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %+v, want an error", in, *v)
+		}
+	}
+}
+
+func TestParseShortForms(t *testing.T) {
+	tests := map[string]string{
+		"1":        "1.0.0",
+		"1.2":      "1.2.0",
+		"1.2.3":    "1.2.3",
+		"1-rc.1":   "1.0.0-rc.1",
+		"1.2+b.01": "1.2.0+b.01",
+	}
+
+	for in, want := range tests {
+		v, err := toVersion(in)
+		if err != nil {
+			t.Errorf("toVersion(%q) failed: %s", in, err)
+			continue
+		}
+		if v.String() != want {
+			t.Errorf("toVersion(%q) = %s, want %s", in, v, want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// in ascending precedence as given by the SemVer spec
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"1.2.0-rc1",
+		"1.2.0",
+		"1.10.0",
+		"2.0.0",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := Parse(ordered[i])
+			b, _ := Parse(ordered[j])
+
+			want := compareInt(i, j)
+			if got := a.Compare(*b); got != want {
+				t.Errorf("%s.Compare(%s) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestCompareIgnoresMetadata(t *testing.T) {
+	a, _ := Parse("1.0.0+build.1")
+	b, _ := Parse("1.0.0+build.2")
+
+	if !a.Equal(*b) {
+		t.Errorf("%s and %s have different precedence", a, b)
+	}
+}
+
+func TestBump(t *testing.T) {
+	v, _ := Parse("1.2.3-rc.1+build")
+
+	tests := map[string]Version{
+		"2.0.0": v.BumpMajor(),
+		"1.3.0": v.BumpMinor(),
+		"1.2.4": v.BumpPatch(),
+		"1.2.3": v.Release(),
+	}
+
+	for want, got := range tests {
+		if got.String() != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+
+	if v.Channel() != "rc" || v.PrereleaseNumber() != 1 {
+		t.Errorf("%s has channel %q and number %d", v, v.Channel(), v.PrereleaseNumber())
+	}
+}
+
+func TestNextPrerelease(t *testing.T) {
+	versions := Versions{}
+	for _, s := range []string{"1.2.0", "1.3.0-rc.1", "1.3.0-rc.2", "1.3.0-alpha.7", "1.3.0-alpha.feat-x.3"} {
+		v, _ := Parse(s)
+		versions = append(versions, *v)
+	}
+
+	tests := []struct {
+		base    string
+		channel string
+		want    string
+	}{
+		{"1.3.0-rc.2", "rc", "1.3.0-rc.3"},
+		{"1.3.0", "alpha", "1.3.0-alpha.8"},
+		{"1.3.0", "alpha.feat-x", "1.3.0-alpha.feat-x.4"},
+		{"1.3.0", "beta", "1.3.0-beta.1"},
+		{"1.4.0", "rc", "1.4.0-rc.1"},
+	}
+
+	for _, test := range tests {
+		base, _ := Parse(test.base)
+		if got := versions.NextPrerelease(*base, test.channel); got.String() != test.want {
+			t.Errorf("NextPrerelease(%s, %s) = %s, want %s", test.base, test.channel, got, test.want)
+		}
+	}
+}
+
+func TestVersionsSorted(t *testing.T) {
+	versions := Versions{}
+	for _, s := range []string{"1.0.5", "1.2.0", "0.9.0", "1.2.0-rc.1", "1.0.5+b"} {
+		v, _ := Parse(s)
+		versions = append(versions, *v)
+	}
+
+	sorted := versions.Sorted()
+	want := []string{"0.9.0", "1.0.5", "1.0.5+b", "1.2.0-rc.1", "1.2.0"}
+	for i := range want {
+		if sorted[i].String() != want[i] {
+			t.Errorf("Sorted()[%d] = %s, want %s", i, sorted[i], want[i])
+		}
+	}
+
+	if versions[0].String() != "1.0.5" {
+		t.Error("Sorted() changed the original order")
+	}
+	if versions.Max().String() != "1.2.0" || versions.Min().String() != "0.9.0" {
+		t.Errorf("Max() = %s, Min() = %s", versions.Max(), versions.Min())
+	}
+	if (Versions{}).Latest() != (Version{}) {
+		t.Error("Latest() of no versions isn't 0.0.0")
+	}
+}